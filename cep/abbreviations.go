@@ -0,0 +1,74 @@
+package cep
+
+import "strings"
+
+// Estrutura de uma abreviação de logradouro ou bairro; bare indica que a forma sem ponto
+// também é expandida (só quando não se confunde com uma palavra comum)
+type abbreviation struct {
+	expanded string
+	bare     bool
+}
+
+// Abreviações comuns nos endereços brasileiros, indexadas sem ponto, em minúsculas e sem acento
+var abbreviations = map[string]abbreviation{
+	"jd":    {"Jardim", true},
+	"jard":  {"Jardim", true},
+	"pq":    {"Parque", true},
+	"vl":    {"Vila", true},
+	"res":   {"Residencial", false},
+	"conj":  {"Conjunto", true},
+	"cj":    {"Conjunto", true},
+	"cond":  {"Condomínio", true},
+	"hab":   {"Habitacional", false},
+	"lot":   {"Loteamento", false},
+	"chac":  {"Chácara", true},
+	"st":    {"Setor", false},
+	"r":     {"Rua", false},
+	"av":    {"Avenida", true},
+	"al":    {"Alameda", false},
+	"tv":    {"Travessa", true},
+	"trav":  {"Travessa", true},
+	"pca":   {"Praça", true},
+	"estr":  {"Estrada", true},
+	"rod":   {"Rodovia", false},
+	"lgo":   {"Largo", true},
+	"sta":   {"Santa", true},
+	"sto":   {"Santo", true},
+	"sra":   {"Senhora", true},
+	"dr":    {"Doutor", true},
+	"prof":  {"Professor", true},
+	"profa": {"Professora", true},
+	"pres":  {"Presidente", false},
+	"gov":   {"Governador", false},
+	"mal":   {"Marechal", false},
+	"gal":   {"General", false},
+	"gen":   {"General", false},
+	"cel":   {"Coronel", false},
+	"cap":   {"Capitão", false},
+	"ten":   {"Tenente", false},
+	"eng":   {"Engenheiro", false},
+	"des":   {"Desembargador", false},
+	"sen":   {"Senador", false},
+	"dep":   {"Deputado", false},
+}
+
+// expandWords troca cada palavra abreviada do texto pela forma por extenso
+func expandWords(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		bare, dotted := strings.CutSuffix(word, ".")
+		a, ok := abbreviations[strings.ToLower(foldText(bare))]
+		if ok && (dotted || a.bare) {
+			words[i] = a.expanded
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ExpandAbbreviations expande as abreviações comuns do logradouro e do bairro ("Jd. Paulista" →
+// "Jardim Paulista", "Pq." → "Parque"), para que respostas de APIs diferentes fiquem comparáveis
+func ExpandAbbreviations(a Address) Address {
+	a.Logradouro = expandWords(a.Logradouro)
+	a.Bairro = expandWords(a.Bairro)
+	return a
+}
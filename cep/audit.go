@@ -0,0 +1,99 @@
+package cep
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+)
+
+// Estrutura de um registro da trilha de auditoria
+type AuditEntry struct {
+	CEP       string
+	Source    string
+	Success   bool
+	Timestamp time.Time
+	RequestID string
+}
+
+// AuditLogger recebe um registro após cada busca feita por FetchFastestAPI
+type AuditLogger interface {
+	Log(ctx context.Context, entry AuditEntry) error
+}
+
+// NoopAuditLogger descarta os registros; é o comportamento quando Config.AuditLogger é nil
+type NoopAuditLogger struct{}
+
+func (NoopAuditLogger) Log(context.Context, AuditEntry) error { return nil }
+
+// PGAuditLogger grava os registros em uma tabela do Postgres. O driver deve ser
+// registrado pela aplicação (ex.: github.com/jackc/pgx/v5/stdlib). Esquema esperado:
+//
+//	CREATE TABLE cep_audit (
+//		cep        TEXT        NOT NULL,
+//		source     TEXT        NOT NULL,
+//		success    BOOLEAN     NOT NULL,
+//		timestamp  TIMESTAMPTZ NOT NULL,
+//		request_id TEXT        NOT NULL
+//	);
+type PGAuditLogger struct {
+	DB    *sql.DB
+	Table string // padrão: cep_audit; aceita apenas letras, dígitos, _ e . (ex.: audit.cep)
+}
+
+// Nomes de tabela aceitos por PGAuditLogger; o nome é montado na SQL e não pode ser um parâmetro
+var auditTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func (l *PGAuditLogger) Log(ctx context.Context, entry AuditEntry) error {
+	table := l.Table
+	if table == "" {
+		table = "cep_audit"
+	}
+	if !auditTableName.MatchString(table) {
+		return fmt.Errorf("nome de tabela de auditoria inválido: %q", table)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (cep, source, success, timestamp, request_id) VALUES ($1, $2, $3, $4, $5)", table)
+	_, err := l.DB.ExecContext(ctx, query, entry.CEP, entry.Source, entry.Success, entry.Timestamp, entry.RequestID)
+	return err
+}
+
+type requestIDKey struct{}
+
+// WithRequestID associa um identificador de requisição ao contexto, registrado na auditoria
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Prazo de cada gravação da auditoria, que acontece antes de a busca retornar
+const auditTimeout = 500 * time.Millisecond
+
+// audit registra a busca; falhas na auditoria não interrompem a busca, apenas são logadas
+func audit(ctx context.Context, config Config, cep string, response APIResponse, err error) {
+	if config.AuditLogger == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		CEP:       cep,
+		Source:    response.Source,
+		Success:   err == nil,
+		Timestamp: time.Now(),
+		RequestID: requestIDFromContext(ctx),
+	}
+	// O contexto da busca pode já ter expirado, mas o registro ainda precisa ser gravado; um
+	// AuditLogger lento atrasa a resposta no máximo auditTimeout
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditTimeout)
+	defer cancel()
+
+	if auditErr := config.AuditLogger.Log(ctx, entry); auditErr != nil {
+		log.Printf("Erro ao gravar auditoria do CEP %s: %v", cep, auditErr)
+	}
+}
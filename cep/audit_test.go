@@ -0,0 +1,39 @@
+package cep
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPGAuditLoggerRejectsTableName(t *testing.T) {
+	for _, table := range []string{"cep_audit; DROP TABLE cep_audit", "cep audit", "1cep", `"cep"`} {
+		// Sem DB: o nome precisa ser recusado antes de qualquer consulta
+		logger := &PGAuditLogger{Table: table}
+		if err := logger.Log(context.Background(), AuditEntry{CEP: "01153000"}); err == nil {
+			t.Errorf("Log aceitou a tabela %q", table)
+		}
+	}
+}
+
+// deadlineAuditLogger guarda o prazo do contexto recebido
+type deadlineAuditLogger struct {
+	deadline time.Time
+}
+
+func (l *deadlineAuditLogger) Log(ctx context.Context, entry AuditEntry) error {
+	l.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+func TestAuditHasItsOwnTimeout(t *testing.T) {
+	logger := &deadlineAuditLogger{}
+	config := DefaultConfig()
+	config.Timeout = time.Minute
+	config.AuditLogger = logger
+
+	audit(context.Background(), config, "01153000", APIResponse{Source: "ViaCEP"}, nil)
+	if remaining := time.Until(logger.deadline); remaining <= 0 || remaining > auditTimeout {
+		t.Errorf("prazo da auditoria = %v, esperava no máximo %v", remaining, auditTimeout)
+	}
+}
@@ -0,0 +1,62 @@
+package cep
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// resultCache guarda respostas bem-sucedidas por CEP durante ttl. Entradas vencidas são
+// ignoradas na leitura e removidas em varreduras feitas, no máximo, uma vez por ttl.
+type resultCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]cacheEntry
+	lastSweep time.Time
+}
+
+type cacheEntry struct {
+	response APIResponse
+	expires  time.Time
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{ttl: ttl, entries: make(map[string]cacheEntry), lastSweep: time.Now()}
+}
+
+func (c *resultCache) get(cep string) (APIResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[cep]
+	if !ok || time.Now().After(entry.expires) {
+		return APIResponse{}, false
+	}
+	return entry.response, true
+}
+
+func (c *resultCache) set(cep string, response APIResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[cep] = cacheEntry{response: response, expires: now.Add(c.ttl)}
+}
+
+// recordCacheHit registra uma busca respondida pelo cache como as demais: nas métricas, na
+// auditoria e no EventSink. ResultSinks não recebem o resultado de novo.
+func recordCacheHit(ctx context.Context, config Config, cep string, response APIResponse, start time.Time) {
+	publishMetrics(config.ExpvarNamespace)
+	metrics.lookups.Add(1)
+	metrics.cacheHits.Add(1)
+	audit(ctx, config, cep, response, nil)
+	publishEvent(ctx, config, cep, response, nil, start)
+}
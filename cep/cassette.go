@@ -0,0 +1,115 @@
+package cep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Modos de gravação/reprodução de respostas (Config.CassetteMode)
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// Cabeçalhos removidos das gravações
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// Estrutura de uma gravação, salva em <CassetteDir>/<API>_<CEP>.json
+type cassette struct {
+	Request struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	} `json:"response"`
+}
+
+func cassettePath(config Config, cep, source string) string {
+	return filepath.Join(config.CassetteDir, source+"_"+cep+".json")
+}
+
+func redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, "REDACTED")
+		}
+	}
+	return header
+}
+
+// doRequest envia a requisição, gravando ou reproduzindo a resposta conforme Config.CassetteMode
+func doRequest(req *http.Request, cep, source string, config Config) (*http.Response, error) {
+	switch config.CassetteMode {
+	case CassetteReplay:
+		return replayCassette(req, cassettePath(config, cep, source))
+	case CassetteRecord:
+		resp, err := clientFor(config, source).Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordCassette(req, resp, body, cassettePath(config, cep, source)); err != nil {
+			log.Printf("Erro ao gravar resposta de %s: %v", source, err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	default:
+		return clientFor(config, source).Do(req)
+	}
+}
+
+func recordCassette(req *http.Request, resp *http.Response, body []byte, path string) error {
+	var c cassette
+	c.Request.Method = req.Method
+	c.Request.URL = req.URL.String()
+	c.Request.Header = redact(req.Header)
+	c.Response.StatusCode = resp.StatusCode
+	c.Response.Header = redact(resp.Header)
+	c.Response.Body = string(body)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func replayCassette(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gravação não encontrada: %w", err)
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("gravação inválida %s: %w", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Response.StatusCode, http.StatusText(c.Response.StatusCode)),
+		StatusCode:    c.Response.StatusCode,
+		Header:        c.Response.Header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(c.Response.Body))),
+		ContentLength: int64(len(c.Response.Body)),
+		Request:       req,
+	}, nil
+}
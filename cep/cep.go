@@ -0,0 +1,129 @@
+package cep
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCEP indica um CEP que não tem 8 dígitos após remover a formatação
+var ErrInvalidCEP = errors.New("CEP inválido")
+
+// stripCEPFormatting remove os separadores aceitos em um CEP ("01.153-000" → "01153000")
+func stripCEPFormatting(cep string) string {
+	return strings.NewReplacer("-", "", ".", "", " ", "").Replace(strings.TrimSpace(cep))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ValidateCEP normaliza o CEP para 8 dígitos ou retorna ErrInvalidCEP
+func ValidateCEP(cep string) (string, error) {
+	if strings.TrimSpace(cep) == "" {
+		return "", fmt.Errorf("%w: CEP vazio", ErrInvalidCEP)
+	}
+
+	normalized := stripCEPFormatting(cep)
+	if len(normalized) != 8 || !isDigits(normalized) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCEP, cep)
+	}
+	return normalized, nil
+}
+
+// ValidateCEPWithSuggestion funciona como ValidateCEP, mas quando o CEP é inválido e a
+// correção é óbvia devolve uma sugestão (ex.: dígito faltando ou sobrando).
+func ValidateCEPWithSuggestion(cep string) (normalized, suggestion string, err error) {
+	normalized, err = ValidateCEP(cep)
+	if err == nil {
+		return normalized, "", nil
+	}
+
+	digits := stripCEPFormatting(cep)
+	if !isDigits(digits) {
+		// Mantém apenas os dígitos quando isso basta para formar um CEP
+		digits = strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, digits)
+		if len(digits) == 8 {
+			return "", digits, err
+		}
+		return "", "", err
+	}
+
+	switch len(digits) {
+	case 7:
+		// Zero à esquerda perdido (CEP guardado como número) ou zero final faltando
+		if digits[0] != '0' {
+			return "", "0" + digits, err
+		}
+		return "", digits + "0", err
+	case 9:
+		return "", digits[:8], err
+	}
+	return "", "", err
+}
+
+// ErrCEPOutOfRange indica um CEP válido, mas fora das faixas permitidas em Config.AllowedCEPRanges
+var ErrCEPOutOfRange = errors.New("CEP fora das faixas permitidas")
+
+// Estrutura para uma faixa numérica de CEPs, inclusiva nas duas pontas
+type CEPRange struct {
+	Start int
+	End   int
+}
+
+// ParseCEPRange interpreta uma faixa no formato "01000000-05999999"
+func ParseCEPRange(s string) (CEPRange, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return CEPRange{}, fmt.Errorf("faixa de CEP inválida: %q", s)
+	}
+
+	start, err := ValidateCEP(startStr)
+	if err != nil {
+		return CEPRange{}, fmt.Errorf("faixa de CEP inválida: %q: %w", s, err)
+	}
+	end, err := ValidateCEP(endStr)
+	if err != nil {
+		return CEPRange{}, fmt.Errorf("faixa de CEP inválida: %q: %w", s, err)
+	}
+
+	r := CEPRange{Start: cepNumber(start), End: cepNumber(end)}
+	if r.Start > r.End {
+		return CEPRange{}, fmt.Errorf("faixa de CEP inválida: %q: início maior que o fim", s)
+	}
+	return r, nil
+}
+
+// cepNumber converte um CEP já normalizado (8 dígitos) em número
+func cepNumber(cep string) int {
+	n := 0
+	for _, r := range cep {
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+// CheckCEPRange verifica se o CEP normalizado está em alguma das faixas; sem faixas, todos são aceitos
+func CheckCEPRange(cep string, ranges []CEPRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	n := cepNumber(cep)
+	for _, r := range ranges {
+		if n >= r.Start && n <= r.End {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrCEPOutOfRange, cep)
+}
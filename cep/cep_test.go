@@ -0,0 +1,25 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestBlankCEPRejectedBeforeNetwork(t *testing.T) {
+	brasilAPI, hits := countingAPI(t, http.StatusOK, brasilAPIBody)
+	config := testConfig(brasilAPI, brasilAPI)
+
+	for _, cep := range []string{"", "  ", "\t\n"} {
+		if _, err := ValidateCEP(cep); !errors.Is(err, ErrInvalidCEP) {
+			t.Errorf("ValidateCEP(%q) = %v, esperava ErrInvalidCEP", cep, err)
+		}
+		if _, err := FetchFastestAPIResponse(context.Background(), cep, config); !errors.Is(err, ErrInvalidCEP) {
+			t.Errorf("FetchFastestAPIResponse(%q) = %v, esperava ErrInvalidCEP", cep, err)
+		}
+	}
+	if hits.Load() != 0 {
+		t.Errorf("requisições às APIs = %d, esperava nenhuma", hits.Load())
+	}
+}
@@ -0,0 +1,34 @@
+package cep
+
+import (
+	"log"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// toUTF8 converte o corpo para UTF-8 quando o Content-Type declara outro charset (ex.:
+// ISO-8859-1 de um proxy mal configurado); sem charset, o corpo é tratado como UTF-8
+func toUTF8(body []byte, contentType, source string) []byte {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+	charset := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return body
+	}
+
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		log.Printf("Charset %q desconhecido na resposta de %s; tratando como UTF-8", charset, source)
+		return body
+	}
+	converted, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		log.Printf("Erro ao converter a resposta de %s de %s para UTF-8: %v", source, charset, err)
+		return body
+	}
+	return converted
+}
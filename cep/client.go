@@ -0,0 +1,69 @@
+package cep
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Valores de Config.Strategy
+const (
+	StrategyFastest  = "fastest"  // todas as APIs correm e a primeira resposta vence (padrão)
+	StrategyFallback = "fallback" // uma API de cada vez, na ordem, até uma responder
+	StrategyMerge    = "merge"    // todas as APIs, combinando campo a campo o que responderam
+)
+
+// Client faz buscas de CEP com uma Config fixa; pode ser usado por várias goroutines ao
+// mesmo tempo. Ajustes de uma única busca são feitos com LookupOption.
+type Client struct {
+	config Config
+	cache  *resultCache // nil sem Config.CacheTTL
+}
+
+// NewClient cria um Client com a configuração (ex.: DefaultConfig ou LoadConfig ajustada)
+func NewClient(config Config) *Client {
+	c := &Client{config: config}
+	if config.CacheTTL > 0 {
+		c.cache = newResultCache(config.CacheTTL)
+	}
+	return c
+}
+
+// Lookup busca o endereço do CEP conforme Config.Strategy. Com Config.CacheTTL, buscas sem
+// opções usam o cache; buscas com opções sempre consultam as APIs e não alteram o cache.
+// Respostas do cache são auditadas e publicadas no EventSink, mas não vão aos ResultSinks.
+func (c *Client) Lookup(ctx context.Context, cep string, opts ...LookupOption) (APIResponse, error) {
+	if c.cache == nil || len(opts) > 0 {
+		return FetchFastestAPIResponse(ctx, cep, c.config, opts...)
+	}
+	start := time.Now()
+
+	// Com PreprocessCEP o CEP pode ainda não ser válido; nesse caso a chave é o texto recebido
+	key := cep
+	if normalized, err := ValidateCEP(cep); err == nil {
+		key = normalized
+	}
+	if response, ok := c.cache.get(key); ok {
+		response.Cached = true
+		recordCacheHit(ctx, c.config, key, response, start)
+		return response, nil
+	}
+
+	response, err := FetchFastestAPIResponse(ctx, cep, c.config)
+	if err == nil {
+		c.cache.set(key, response)
+	}
+	return response, err
+}
+
+// WithStrategy troca a estratégia apenas nesta chamada
+func WithStrategy(strategy string) LookupOption {
+	return func(config *Config) error {
+		switch strategy {
+		case "", StrategyFastest, StrategyFallback, StrategyMerge:
+			config.Strategy = strategy
+			return nil
+		}
+		return fmt.Errorf("estratégia desconhecida: %q", strategy)
+	}
+}
@@ -0,0 +1,179 @@
+package cep
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Rode com -race: muitas goroutines compartilham a mesma Config e o mesmo Client
+func TestConcurrentLookupsShareConfig(t *testing.T) {
+	brasilAPI := stubAPI(t, http.StatusOK, brasilAPIBody, time.Millisecond)
+	viaCEP := stubAPI(t, http.StatusInternalServerError, "", 0)
+	config := testConfig(brasilAPI, viaCEP)
+	config.ExpvarNamespace = "cep_test"
+	config.RetryOnStatus = []int{}
+	cached := config
+	cached.CacheTTL = time.Minute
+	client := NewClient(cached)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				var err error
+				if i%2 == 0 {
+					_, err = FetchFastestAPIResponse(context.Background(), "01153000", config)
+				} else {
+					_, err = client.Lookup(context.Background(), "01153-000")
+				}
+				if err != nil {
+					t.Errorf("busca falhou: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// staticProvider é uma fonte registrada que responde sempre o mesmo endereço
+type staticProvider struct {
+	name    string
+	address Address
+}
+
+func (p staticProvider) Name() string { return p.name }
+
+func (p staticProvider) Lookup(ctx context.Context, cep string) (Address, error) {
+	return p.address, nil
+}
+
+// registerForTest registra a fonte e a remove do registro no fim do teste
+func registerForTest(t *testing.T, p Provider) {
+	t.Helper()
+	RegisterProvider(p)
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		delete(registry.providers, p.Name())
+		registry.names = slices.DeleteFunc(registry.names, func(name string) bool { return name == p.Name() })
+	})
+}
+
+func TestRegisterProviderPanics(t *testing.T) {
+	registerForTest(t, staticProvider{name: "Interna"})
+
+	tests := []struct {
+		name     string
+		provider Provider
+	}{
+		{"nome vazio", staticProvider{}},
+		{"nome de API embutida", staticProvider{name: "ViaCEP"}},
+		{"registrada duas vezes", staticProvider{name: "Interna"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterProvider(%q) não entrou em pânico", tt.provider.Name())
+				}
+			}()
+			RegisterProvider(tt.provider)
+		})
+	}
+}
+
+func TestRegisteredProviderWins(t *testing.T) {
+	slow := stubAPI(t, http.StatusOK, brasilAPIBody, 300*time.Millisecond)
+	registerForTest(t, staticProvider{name: "Interna", address: Address{
+		CEP: "01153000", Logradouro: "Rua Vitorino Carmilo", Bairro: "Barra Funda", Cidade: "São Paulo", UF: "SP",
+	}})
+
+	response, err := NewClient(testConfig(slow, slow)).Lookup(context.Background(), "01153000")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Source != "Interna" {
+		t.Errorf("fonte = %s, esperava a fonte registrada", response.Source)
+	}
+}
+
+func TestStrategyFallbackOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		brasilAPIStatus int
+		wantSource      string
+		wantViaCEPHits  int32
+	}{
+		{"primeira responde", http.StatusOK, "BrasilAPI", 0},
+		{"primeira falha", http.StatusInternalServerError, "ViaCEP", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brasilAPI, brasilAPIHits := countingAPI(t, tt.brasilAPIStatus, brasilAPIBody)
+			viaCEP, viaCEPHits := countingAPI(t, http.StatusOK, viaCEPBody)
+			config := testConfig(brasilAPI, viaCEP)
+			config.DisableRetries = true
+
+			response, err := NewClient(config).Lookup(context.Background(), "01153000", WithStrategy(StrategyFallback))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if response.Source != tt.wantSource {
+				t.Errorf("fonte = %s, esperava %s", response.Source, tt.wantSource)
+			}
+			if brasilAPIHits.Load() != 1 || viaCEPHits.Load() != tt.wantViaCEPHits {
+				t.Errorf("requisições: BrasilAPI = %d, ViaCEP = %d; esperava 1 e %d", brasilAPIHits.Load(), viaCEPHits.Load(), tt.wantViaCEPHits)
+			}
+		})
+	}
+}
+
+func TestStrategyMergeCombinesFields(t *testing.T) {
+	// Cada API deixa de fora um campo que a outra tem
+	brasilAPI := stubAPI(t, http.StatusOK, `{"cep":"01153000","state":"SP","city":"São Paulo","neighborhood":"","street":"Rua Vitorino Carmilo"}`, 0)
+	viaCEP := stubAPI(t, http.StatusOK, `{"cep":"01153-000","logradouro":"","bairro":"Barra Funda","localidade":"São Paulo","uf":"SP"}`, 0)
+	config := testConfig(brasilAPI, viaCEP)
+	config.DisableRetries = true
+
+	response, err := NewClient(config).Lookup(context.Background(), "01153000", WithStrategy(StrategyMerge))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Result.Logradouro != "Rua Vitorino Carmilo" || response.Result.Bairro != "Barra Funda" {
+		t.Errorf("endereço = %+v, esperava o logradouro da BrasilAPI e o bairro do ViaCEP", response.Result)
+	}
+	for _, name := range []string{"BrasilAPI", "ViaCEP"} {
+		if !strings.Contains(response.Source, name) {
+			t.Errorf("fonte = %s, esperava %s entre as combinadas", response.Source, name)
+		}
+	}
+}
+
+func TestLookupAsyncUsesClientCache(t *testing.T) {
+	brasilAPI, hits := countingAPI(t, http.StatusOK, brasilAPIBody)
+	config := testConfig(brasilAPI, brasilAPI)
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+	config.CacheTTL = time.Minute
+	client := NewClient(config)
+
+	for range 2 {
+		response, err := client.LookupAsync(context.Background(), "01153000").Wait()
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if response.Result.Logradouro != "Rua Vitorino Carmilo" {
+			t.Errorf("endereço = %+v", response.Result)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("requisições = %d, esperava 1 (a segunda busca do cache)", hits.Load())
+	}
+}
@@ -0,0 +1,971 @@
+package cep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultConfig devolve a configuração padrão: URLs públicas das APIs e prazo de 1s
+func DefaultConfig() Config {
+	return Config{
+		BrasilAPIURL: "https://brasilapi.com.br/api/cep/v1/",
+		ViaCEPURL:    "https://viacep.com.br/ws/",
+		OpenCEPURL:   "https://opencep.com/v1/",
+		Timeout:      1 * time.Second,
+		DNSCooldown:  30 * time.Second,
+
+		MaxRetries:             defaultMaxRetries,
+		RetryTimeoutMultiplier: 1.0,
+		CassetteDir:            "cassettes",
+	}
+}
+
+// LoadConfig parte de DefaultConfig, aplica as variáveis de ambiente e valida o resultado
+func LoadConfig() (Config, error) {
+	config := DefaultConfig()
+	applyEnv(&config)
+	if err := validateConfig(config); err != nil {
+		return Config{}, fmt.Errorf("variáveis de ambiente: %w", err)
+	}
+	return config, nil
+}
+
+// Estrutura do arquivo de configuração (JSON); campos ausentes mantêm o valor padrão
+type fileConfig struct {
+	BrasilAPIURL            *string                          `json:"brasil_api_url"`
+	BrasilAPIV2URL          *string                          `json:"brasil_api_v2_url"`
+	ViaCEPURL               *string                          `json:"viacep_url"`
+	OpenCEPURL              *string                          `json:"opencep_url"`
+	Timeout                 *string                          `json:"timeout"`
+	DatasetPath             *string                          `json:"dataset_path"`
+	Strategy                *string                          `json:"strategy"`
+	Locale                  *string                          `json:"locale"`
+	ExpvarNamespace         *string                          `json:"expvar_namespace"`
+	DerivePartialAddress    *bool                            `json:"derive_partial_address"`
+	DisallowUnknownFields   *bool                            `json:"disallow_unknown_fields"`
+	StrictContentType       *bool                            `json:"strict_content_type"`
+	CaptureExtraFields      *bool                            `json:"capture_extra_fields"`
+	BrasilAPIServiceSource  *bool                            `json:"brasil_api_service_source"`
+	DryRun                  *bool                            `json:"dry_run"`
+	CaptureHeaders          []string                         `json:"capture_headers"`
+	RequiredFields          *string                          `json:"required_fields"`
+	RetryOnEmpty            *bool                            `json:"retry_on_empty"`
+	EmptyFields             *string                          `json:"empty_fields"`
+	MinAgreement            *int                             `json:"min_agreement"`
+	TieBreakerProvider      *string                          `json:"tie_breaker_provider"`
+	CEPMismatch             *string                          `json:"cep_mismatch"`
+	UseRequestedCEP         *bool                            `json:"use_requested_cep"`
+	CanonicalCEPSource      *string                          `json:"canonical_cep_source"`
+	DNSCooldown             *string                          `json:"dns_cooldown"`
+	FailFastWhenAllDown     *bool                            `json:"fail_fast_when_all_down"`
+	AllowedCEPRanges        []string                         `json:"allowed_cep_ranges"`
+	RetryOnStatus           []int                            `json:"retry_on_status"`
+	ProviderRetryOnStatus   map[string][]int                 `json:"provider_retry_on_status"`
+	DeprecatedProviders     []string                         `json:"deprecated_providers"`
+	StandbyProviders        []string                         `json:"standby_providers"`
+	StandbyErrorRate        *float64                         `json:"standby_error_rate"`
+	ProviderEndpoints       map[string][]ProviderEndpoint    `json:"provider_endpoints"`
+	AttemptTimeout          *string                          `json:"attempt_timeout"`
+	MaxAcceptableLatency    *string                          `json:"max_acceptable_latency"`
+	CollectRunnerUp         *string                          `json:"collect_runner_up"`
+	CacheTTL                *string                          `json:"cache_ttl"`
+	ServerWorkers           *int                             `json:"server_workers"`
+	MaxRetries              *int                             `json:"max_retries"`
+	DisableRetries          *bool                            `json:"disable_retries"`
+	ProviderMaxRetries      map[string]int                   `json:"provider_max_retries"`
+	PrefixTimeouts          map[string]string                `json:"prefix_timeouts"`
+	ProviderAttemptTimeouts map[string]string                `json:"provider_attempt_timeouts"`
+	DialTimeout             *string                          `json:"dial_timeout"`
+	TimeoutLadder           []string                         `json:"timeout_ladder"`
+	ProviderTiers           [][]string                       `json:"provider_tiers"`
+	RegionPreferences       map[string][]string              `json:"region_preferences"`
+	IdleConnTimeout         *string                          `json:"idle_conn_timeout"`
+	MaxIdleConnsPerHost     *int                             `json:"max_idle_conns_per_host"`
+	IsolateProviders        *bool                            `json:"isolate_providers"`
+	ProviderTransports      map[string]fileProviderTransport `json:"provider_transports"`
+	MaxRedirects            *int                             `json:"max_redirects"`
+	HTTP2                   *string                          `json:"http2"`
+	IPFamily                *string                          `json:"ip_family"`
+	DialFallbackDelay       *string                          `json:"dial_fallback_delay"`
+	RetryTimeoutMultiplier  *float64                         `json:"retry_timeout_multiplier"`
+	CassetteMode            *string                          `json:"cassette_mode"`
+	CassetteDir             *string                          `json:"cassette_dir"`
+	ASCIIFold               *bool                            `json:"ascii_fold"`
+	ExpandAbbreviations     *bool                            `json:"expand_abbreviations"`
+	DefaultFieldValue       *string                          `json:"default_field_value"`
+	ErrorBodySnippetBytes   *int                             `json:"error_body_snippet_bytes"`
+}
+
+// Estrutura de provider_transports no arquivo; idle_conn_timeout é uma duração (ex.: "90s")
+type fileProviderTransport struct {
+	MaxConnsPerHost     int    `json:"max_conns_per_host"`
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host"`
+	IdleConnTimeout     string `json:"idle_conn_timeout"`
+	DialTimeout         string `json:"dial_timeout"`
+}
+
+// LoadConfigFromFile lê a configuração de um arquivo JSON. Variáveis de ambiente
+// definidas têm precedência sobre os valores do arquivo.
+func LoadConfigFromFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	var file fileConfig
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("arquivo de configuração %s: %w", path, err)
+	}
+
+	config := DefaultConfig()
+	if file.BrasilAPIURL != nil {
+		config.BrasilAPIURL = *file.BrasilAPIURL
+	}
+	if file.BrasilAPIV2URL != nil {
+		config.BrasilAPIV2URL = *file.BrasilAPIV2URL
+	}
+	if file.ViaCEPURL != nil {
+		config.ViaCEPURL = *file.ViaCEPURL
+	}
+	if file.OpenCEPURL != nil {
+		config.OpenCEPURL = *file.OpenCEPURL
+	}
+	if file.Timeout != nil {
+		timeout, err := time.ParseDuration(*file.Timeout)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: timeout: %w", path, err)
+		}
+		config.Timeout = timeout
+	}
+	if file.DatasetPath != nil {
+		config.DatasetPath = *file.DatasetPath
+	}
+	if file.Strategy != nil {
+		config.Strategy = *file.Strategy
+	}
+	if file.Locale != nil {
+		config.Locale = *file.Locale
+	}
+	if file.ExpvarNamespace != nil {
+		config.ExpvarNamespace = *file.ExpvarNamespace
+	}
+	if file.DerivePartialAddress != nil {
+		config.DerivePartialAddress = *file.DerivePartialAddress
+	}
+	if file.DisallowUnknownFields != nil {
+		config.DisallowUnknownFields = *file.DisallowUnknownFields
+	}
+	if file.StrictContentType != nil {
+		config.StrictContentType = *file.StrictContentType
+	}
+	if file.CaptureExtraFields != nil {
+		config.CaptureExtraFields = *file.CaptureExtraFields
+	}
+	if file.BrasilAPIServiceSource != nil {
+		config.BrasilAPIServiceSource = *file.BrasilAPIServiceSource
+	}
+	if file.DryRun != nil {
+		config.DryRun = *file.DryRun
+	}
+	if file.CaptureHeaders != nil {
+		config.CaptureHeaders = file.CaptureHeaders
+	}
+	if file.RequiredFields != nil {
+		fields, err := ParseAddressFields(*file.RequiredFields)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: required_fields: %w", path, err)
+		}
+		config.RequiredFields = fields
+	}
+	if file.RetryOnEmpty != nil {
+		config.RetryOnEmpty = *file.RetryOnEmpty
+	}
+	if file.EmptyFields != nil {
+		fields, err := ParseAddressFields(*file.EmptyFields)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: empty_fields: %w", path, err)
+		}
+		config.EmptyFields = fields
+	}
+	if file.MinAgreement != nil {
+		config.MinAgreement = *file.MinAgreement
+	}
+	if file.TieBreakerProvider != nil {
+		config.TieBreakerProvider = *file.TieBreakerProvider
+	}
+	if file.CEPMismatch != nil {
+		config.CEPMismatch = *file.CEPMismatch
+	}
+	if file.UseRequestedCEP != nil {
+		config.UseRequestedCEP = *file.UseRequestedCEP
+	}
+	if file.CanonicalCEPSource != nil {
+		config.CanonicalCEPSource = *file.CanonicalCEPSource
+	}
+
+	if file.DNSCooldown != nil {
+		cooldown, err := time.ParseDuration(*file.DNSCooldown)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: dns_cooldown: %w", path, err)
+		}
+		config.DNSCooldown = cooldown
+	}
+	if file.FailFastWhenAllDown != nil {
+		config.FailFastWhenAllDown = *file.FailFastWhenAllDown
+	}
+
+	if file.AllowedCEPRanges != nil {
+		ranges, err := parseCEPRanges(file.AllowedCEPRanges)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: %w", path, err)
+		}
+		config.AllowedCEPRanges = ranges
+	}
+
+	if file.RetryOnStatus != nil {
+		config.RetryOnStatus = file.RetryOnStatus
+	}
+	if file.ProviderRetryOnStatus != nil {
+		config.ProviderRetryOnStatus = file.ProviderRetryOnStatus
+	}
+	if file.DeprecatedProviders != nil {
+		config.DeprecatedProviders = file.DeprecatedProviders
+	}
+	if file.StandbyProviders != nil {
+		config.StandbyProviders = file.StandbyProviders
+	}
+	if file.StandbyErrorRate != nil {
+		config.StandbyErrorRate = *file.StandbyErrorRate
+	}
+	if file.ProviderEndpoints != nil {
+		config.ProviderEndpoints = file.ProviderEndpoints
+	}
+
+	if file.AttemptTimeout != nil {
+		timeout, err := time.ParseDuration(*file.AttemptTimeout)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: attempt_timeout: %w", path, err)
+		}
+		config.AttemptTimeout = timeout
+	}
+	if file.MaxAcceptableLatency != nil {
+		latency, err := time.ParseDuration(*file.MaxAcceptableLatency)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: max_acceptable_latency: %w", path, err)
+		}
+		config.MaxAcceptableLatency = latency
+	}
+	if file.MaxRetries != nil {
+		config.MaxRetries = *file.MaxRetries
+	}
+	if file.ProviderMaxRetries != nil {
+		config.ProviderMaxRetries = file.ProviderMaxRetries
+	}
+	if file.DisableRetries != nil {
+		config.DisableRetries = *file.DisableRetries
+	}
+	if file.CollectRunnerUp != nil {
+		wait, err := time.ParseDuration(*file.CollectRunnerUp)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: collect_runner_up: %w", path, err)
+		}
+		config.CollectRunnerUp = wait
+	}
+	if file.CacheTTL != nil {
+		ttl, err := time.ParseDuration(*file.CacheTTL)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: cache_ttl: %w", path, err)
+		}
+		config.CacheTTL = ttl
+	}
+	if file.ServerWorkers != nil {
+		config.ServerWorkers = *file.ServerWorkers
+	}
+	if file.IdleConnTimeout != nil {
+		timeout, err := time.ParseDuration(*file.IdleConnTimeout)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: idle_conn_timeout: %w", path, err)
+		}
+		config.IdleConnTimeout = timeout
+	}
+	if file.MaxIdleConnsPerHost != nil {
+		config.MaxIdleConnsPerHost = *file.MaxIdleConnsPerHost
+	}
+	if file.IsolateProviders != nil {
+		config.IsolateProviders = *file.IsolateProviders
+	}
+	if file.ProviderTransports != nil {
+		config.ProviderTransports = make(map[string]ProviderTransport, len(file.ProviderTransports))
+		for name, t := range file.ProviderTransports {
+			transport := ProviderTransport{MaxConnsPerHost: t.MaxConnsPerHost, MaxIdleConnsPerHost: t.MaxIdleConnsPerHost}
+			if t.IdleConnTimeout != "" {
+				timeout, err := time.ParseDuration(t.IdleConnTimeout)
+				if err != nil {
+					return Config{}, fmt.Errorf("arquivo de configuração %s: provider_transports: idle_conn_timeout inválido para %s: %w", path, name, err)
+				}
+				transport.IdleConnTimeout = timeout
+			}
+			if t.DialTimeout != "" {
+				timeout, err := time.ParseDuration(t.DialTimeout)
+				if err != nil {
+					return Config{}, fmt.Errorf("arquivo de configuração %s: provider_transports: dial_timeout inválido para %s: %w", path, name, err)
+				}
+				transport.DialTimeout = timeout
+			}
+			config.ProviderTransports[name] = transport
+		}
+	}
+	if file.MaxRedirects != nil {
+		config.MaxRedirects = *file.MaxRedirects
+	}
+	if file.HTTP2 != nil {
+		config.HTTP2 = *file.HTTP2
+	}
+	if file.IPFamily != nil {
+		config.IPFamily = *file.IPFamily
+	}
+	if file.DialFallbackDelay != nil {
+		delay, err := time.ParseDuration(*file.DialFallbackDelay)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: dial_fallback_delay: %w", path, err)
+		}
+		config.DialFallbackDelay = delay
+	}
+	if file.ProviderTiers != nil {
+		config.ProviderTiers = file.ProviderTiers
+	}
+	if file.RegionPreferences != nil {
+		config.RegionPreferences = file.RegionPreferences
+	}
+	if file.PrefixTimeouts != nil {
+		config.PrefixTimeouts = make(map[string]time.Duration, len(file.PrefixTimeouts))
+		for prefix, v := range file.PrefixTimeouts {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("arquivo de configuração %s: prefix_timeouts: %s: %w", path, prefix, err)
+			}
+			config.PrefixTimeouts[prefix] = timeout
+		}
+	}
+	if file.ProviderAttemptTimeouts != nil {
+		config.ProviderAttemptTimeouts = make(map[string]time.Duration, len(file.ProviderAttemptTimeouts))
+		for name, v := range file.ProviderAttemptTimeouts {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("arquivo de configuração %s: provider_attempt_timeouts: %s: %w", path, name, err)
+			}
+			config.ProviderAttemptTimeouts[name] = timeout
+		}
+	}
+	if file.DialTimeout != nil {
+		timeout, err := time.ParseDuration(*file.DialTimeout)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: dial_timeout: %w", path, err)
+		}
+		config.DialTimeout = timeout
+	}
+	if file.TimeoutLadder != nil {
+		ladder, err := parseDurations(file.TimeoutLadder)
+		if err != nil {
+			return Config{}, fmt.Errorf("arquivo de configuração %s: timeout_ladder: %w", path, err)
+		}
+		config.TimeoutLadder = ladder
+	}
+	if file.RetryTimeoutMultiplier != nil {
+		config.RetryTimeoutMultiplier = *file.RetryTimeoutMultiplier
+	}
+
+	if file.CassetteMode != nil {
+		config.CassetteMode = *file.CassetteMode
+	}
+	if file.CassetteDir != nil {
+		config.CassetteDir = *file.CassetteDir
+	}
+
+	if file.ASCIIFold != nil {
+		config.ASCIIFold = *file.ASCIIFold
+	}
+	if file.ExpandAbbreviations != nil {
+		config.ExpandAbbreviations = *file.ExpandAbbreviations
+	}
+	if file.DefaultFieldValue != nil {
+		config.DefaultFieldValue = *file.DefaultFieldValue
+	}
+	if file.ErrorBodySnippetBytes != nil {
+		config.ErrorBodySnippetBytes = *file.ErrorBodySnippetBytes
+	}
+
+	applyEnv(&config)
+
+	if err := validateConfig(config); err != nil {
+		return Config{}, fmt.Errorf("arquivo de configuração %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func validateConfig(config Config) error {
+	var errs []error
+	bases := map[string]string{
+		"BrasilAPI": config.BrasilAPIURL,
+		"ViaCEP":    config.ViaCEPURL,
+		"OpenCEP":   config.OpenCEPURL,
+	}
+	for name, base := range bases {
+		if u, err := url.Parse(base); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("URL inválida para %s: %q", name, base))
+		}
+	}
+	for _, name := range config.StandbyProviders {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("standby_providers: API desconhecida: %s", name))
+		}
+	}
+	if config.StandbyErrorRate < 0 || config.StandbyErrorRate > 1 {
+		errs = append(errs, fmt.Errorf("standby_error_rate deve estar entre 0 e 1: %v", config.StandbyErrorRate))
+	}
+	for _, name := range config.DeprecatedProviders {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("deprecated_providers: API desconhecida: %s", name))
+		}
+	}
+	for name, endpoints := range config.ProviderEndpoints {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("provider_endpoints: API desconhecida: %s", name))
+		}
+		for _, e := range endpoints {
+			if u, err := url.Parse(e.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("URL inválida para espelho de %s: %q", name, e.URL))
+			}
+		}
+	}
+	if config.BrasilAPIV2URL != "" {
+		if u, err := url.Parse(config.BrasilAPIV2URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("URL inválida para BrasilAPI v2: %q", config.BrasilAPIV2URL))
+		}
+	}
+	if config.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("timeout deve ser positivo: %v", config.Timeout))
+	}
+	if config.AttemptTimeout < 0 {
+		errs = append(errs, fmt.Errorf("attempt_timeout não pode ser negativo: %v", config.AttemptTimeout))
+	}
+	if config.MaxAcceptableLatency < 0 {
+		errs = append(errs, fmt.Errorf("max_acceptable_latency não pode ser negativo: %v", config.MaxAcceptableLatency))
+	}
+	if config.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("max_retries não pode ser negativo: %d", config.MaxRetries))
+	}
+	for name, retries := range config.ProviderMaxRetries {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("provider_max_retries: API desconhecida: %s", name))
+		}
+		if retries < 0 {
+			errs = append(errs, fmt.Errorf("provider_max_retries não pode ser negativo: %s=%d", name, retries))
+		}
+	}
+	if config.CollectRunnerUp < 0 {
+		errs = append(errs, fmt.Errorf("collect_runner_up não pode ser negativo: %v", config.CollectRunnerUp))
+	}
+	if config.ServerWorkers < 0 {
+		errs = append(errs, fmt.Errorf("server_workers não pode ser negativo: %d", config.ServerWorkers))
+	}
+	if config.IdleConnTimeout < 0 {
+		errs = append(errs, fmt.Errorf("idle_conn_timeout não pode ser negativo: %v", config.IdleConnTimeout))
+	}
+	if config.MaxIdleConnsPerHost < 0 {
+		errs = append(errs, fmt.Errorf("max_idle_conns_per_host não pode ser negativo: %d", config.MaxIdleConnsPerHost))
+	}
+	for name, t := range config.ProviderTransports {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("provider_transports: API desconhecida: %s", name))
+		}
+		if t.MaxConnsPerHost < 0 || t.MaxIdleConnsPerHost < 0 || t.IdleConnTimeout < 0 || t.DialTimeout < 0 {
+			errs = append(errs, fmt.Errorf("provider_transports: limites negativos para %s", name))
+		}
+	}
+	for _, tier := range config.ProviderTiers {
+		for _, name := range tier {
+			if !slices.Contains(providerNames(), name) {
+				errs = append(errs, fmt.Errorf("provider_tiers: API desconhecida: %s", name))
+			}
+		}
+	}
+	for prefix, timeout := range config.PrefixTimeouts {
+		if !isDigits(prefix) || len(prefix) > 8 || timeout <= 0 {
+			errs = append(errs, fmt.Errorf("prefix_timeouts inválido: %q=%v", prefix, timeout))
+		}
+	}
+	for name, timeout := range config.ProviderAttemptTimeouts {
+		if !slices.Contains(providerNames(), name) {
+			errs = append(errs, fmt.Errorf("provider_attempt_timeouts: API desconhecida: %s", name))
+		}
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("provider_attempt_timeouts não pode ser negativo: %s=%v", name, timeout))
+		}
+	}
+	if config.DialTimeout < 0 {
+		errs = append(errs, fmt.Errorf("dial_timeout não pode ser negativo: %v", config.DialTimeout))
+	}
+	for prefix, providers := range config.RegionPreferences {
+		if !isDigits(prefix) || len(prefix) > 8 {
+			errs = append(errs, fmt.Errorf("region_preferences: prefixo inválido: %q", prefix))
+		}
+		for _, name := range providers {
+			if !slices.Contains(providerNames(), name) {
+				errs = append(errs, fmt.Errorf("region_preferences: API desconhecida: %s", name))
+			}
+		}
+	}
+	for _, timeout := range config.TimeoutLadder {
+		if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("timeout_ladder deve ter apenas prazos positivos: %v", timeout))
+		}
+	}
+	if config.RetryTimeoutMultiplier < 1 {
+		errs = append(errs, fmt.Errorf("retry_timeout_multiplier deve ser pelo menos 1: %v", config.RetryTimeoutMultiplier))
+	}
+	switch config.CassetteMode {
+	case "", CassetteRecord, CassetteReplay:
+	default:
+		errs = append(errs, fmt.Errorf("cassette_mode inválido: %q", config.CassetteMode))
+	}
+	if source := config.CanonicalCEPSource; source != "" && source != CanonicalCEPRequested && !slices.Contains(providerNames(), source) {
+		errs = append(errs, fmt.Errorf("canonical_cep_source inválido: %q", source))
+	}
+	if p := config.TieBreakerProvider; p != "" && !slices.Contains(providerNames(), p) {
+		errs = append(errs, fmt.Errorf("tie_breaker_provider: API desconhecida: %s", p))
+	}
+	if config.ErrorBodySnippetBytes < 0 {
+		errs = append(errs, fmt.Errorf("error_body_snippet_bytes não pode ser negativo: %d", config.ErrorBodySnippetBytes))
+	}
+	switch config.CEPMismatch {
+	case "", CEPMismatchReject, CEPMismatchRetry:
+	default:
+		errs = append(errs, fmt.Errorf("cep_mismatch inválido: %q", config.CEPMismatch))
+	}
+	switch config.Strategy {
+	case "", StrategyFastest, StrategyFallback, StrategyMerge:
+	default:
+		errs = append(errs, fmt.Errorf("strategy inválida: %q", config.Strategy))
+	}
+	switch config.Locale {
+	case "", LocalePortuguese, LocaleEnglish:
+	default:
+		errs = append(errs, fmt.Errorf("locale inválido: %q", config.Locale))
+	}
+	switch config.HTTP2 {
+	case "", HTTP2Force, HTTP2Disable:
+	default:
+		errs = append(errs, fmt.Errorf("http2 inválido: %q", config.HTTP2))
+	}
+	switch config.IPFamily {
+	case "", IPFamilyIPv4, IPFamilyIPv6:
+	default:
+		errs = append(errs, fmt.Errorf("ip_family inválido: %q", config.IPFamily))
+	}
+	if config.MinAgreement < 0 {
+		errs = append(errs, fmt.Errorf("min_agreement não pode ser negativo: %d", config.MinAgreement))
+	}
+	return errors.Join(errs...)
+}
+
+// applyEnv sobrescreve a configuração com as variáveis de ambiente definidas
+func applyEnv(config *Config) {
+	if v := os.Getenv("BRASIL_API_URL"); v != "" {
+		config.BrasilAPIURL = v
+	}
+	if v := os.Getenv("BRASIL_API_V2_URL"); v != "" {
+		config.BrasilAPIV2URL = v
+	}
+	if v := os.Getenv("VIACEP_URL"); v != "" {
+		config.ViaCEPURL = v
+	}
+	if v := os.Getenv("OPENCEP_URL"); v != "" {
+		config.OpenCEPURL = v
+	}
+	if v := os.Getenv("API_TIMEOUT"); v != "" {
+		if t, err := time.ParseDuration(v); err == nil {
+			config.Timeout = t
+		}
+	}
+	if v := os.Getenv("DNS_COOLDOWN"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.DNSCooldown = d
+		}
+	}
+	if v := os.Getenv("ATTEMPT_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.AttemptTimeout = d
+		}
+	}
+	if v := os.Getenv("MAX_ACCEPTABLE_LATENCY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.MaxAcceptableLatency = d
+		}
+	}
+	if v := os.Getenv("MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.MaxRetries = n
+		}
+	}
+	if v := os.Getenv("PROVIDER_MAX_RETRIES"); v != "" {
+		if retries, err := parseRetries(v); err == nil {
+			config.ProviderMaxRetries = retries
+		} else {
+			log.Println("Ignorando PROVIDER_MAX_RETRIES:", err)
+		}
+	}
+	if v := os.Getenv("COLLECT_RUNNER_UP"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.CollectRunnerUp = d
+		}
+	}
+	if v := os.Getenv("CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.CacheTTL = d
+		}
+	}
+	if v := os.Getenv("SERVER_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.ServerWorkers = n
+		}
+	}
+	if v := os.Getenv("IDLE_CONN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.IdleConnTimeout = d
+		}
+	}
+	if v := os.Getenv("MAX_IDLE_CONNS_PER_HOST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.MaxIdleConnsPerHost = n
+		}
+	}
+	if v := os.Getenv("MAX_REDIRECTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.MaxRedirects = n
+		}
+	}
+	if v := os.Getenv("HTTP2"); v != "" {
+		config.HTTP2 = v
+	}
+	if v := os.Getenv("IP_FAMILY"); v != "" {
+		config.IPFamily = v
+	}
+	if v := os.Getenv("DIAL_FALLBACK_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.DialFallbackDelay = d
+		}
+	}
+	if v := os.Getenv("PROVIDER_TIERS"); v != "" {
+		var tiers [][]string
+		for _, tier := range strings.Split(v, ";") {
+			tiers = append(tiers, strings.Split(strings.ReplaceAll(tier, " ", ""), ","))
+		}
+		config.ProviderTiers = tiers
+	}
+	if v := os.Getenv("PROVIDER_ATTEMPT_TIMEOUTS"); v != "" {
+		if timeouts, err := parseTimeouts(v); err == nil {
+			config.ProviderAttemptTimeouts = timeouts
+		} else {
+			log.Println("Ignorando PROVIDER_ATTEMPT_TIMEOUTS:", err)
+		}
+	}
+	if v := os.Getenv("DIAL_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			config.DialTimeout = d
+		}
+	}
+	if v := os.Getenv("CEP_PREFIX_TIMEOUTS"); v != "" {
+		if timeouts, err := parseTimeouts(v); err == nil {
+			config.PrefixTimeouts = timeouts
+		} else {
+			log.Println("Ignorando CEP_PREFIX_TIMEOUTS:", err)
+		}
+	}
+	if v := os.Getenv("TIMEOUT_LADDER"); v != "" {
+		if ladder, err := parseDurations(strings.Split(v, ",")); err == nil {
+			config.TimeoutLadder = ladder
+		} else {
+			log.Println("Ignorando TIMEOUT_LADDER:", err)
+		}
+	}
+	if v := os.Getenv("RETRY_TIMEOUT_MULTIPLIER"); v != "" {
+		if m, err := strconv.ParseFloat(v, 64); err == nil {
+			config.RetryTimeoutMultiplier = m
+		}
+	}
+	if v := os.Getenv("CASSETTE_MODE"); v != "" {
+		config.CassetteMode = v
+	}
+	if v := os.Getenv("CASSETTE_DIR"); v != "" {
+		config.CassetteDir = v
+	}
+	if v := os.Getenv("DEFAULT_FIELD_VALUE"); v != "" {
+		config.DefaultFieldValue = v
+	}
+	if v := os.Getenv("CEP_MISMATCH"); v != "" {
+		config.CEPMismatch = v
+	}
+	if v := os.Getenv("CANONICAL_CEP_SOURCE"); v != "" {
+		config.CanonicalCEPSource = v
+	}
+	if v := os.Getenv("CEP_DATASET_PATH"); v != "" {
+		config.DatasetPath = v
+	}
+	if v := os.Getenv("LOOKUP_STRATEGY"); v != "" {
+		config.Strategy = v
+	}
+	if v := os.Getenv("LOCALE"); v != "" {
+		config.Locale = v
+	}
+	if v := os.Getenv("EXPVAR_NAMESPACE"); v != "" {
+		config.ExpvarNamespace = v
+	}
+
+	envBool("DISALLOW_UNKNOWN_FIELDS", &config.DisallowUnknownFields)
+	envBool("ISOLATE_PROVIDERS", &config.IsolateProviders)
+	envBool("DISABLE_RETRIES", &config.DisableRetries)
+	envBool("FAIL_FAST_WHEN_ALL_DOWN", &config.FailFastWhenAllDown)
+	envBool("DERIVE_PARTIAL_ADDRESS", &config.DerivePartialAddress)
+	envBool("STRICT_CONTENT_TYPE", &config.StrictContentType)
+	envBool("CAPTURE_EXTRA_FIELDS", &config.CaptureExtraFields)
+	envBool("BRASIL_API_SERVICE_SOURCE", &config.BrasilAPIServiceSource)
+	envBool("DRY_RUN", &config.DryRun)
+	envBool("USE_REQUESTED_CEP", &config.UseRequestedCEP)
+	envBool("ASCII_FOLD", &config.ASCIIFold)
+	envBool("EXPAND_ABBREVIATIONS", &config.ExpandAbbreviations)
+	envBool("RETRY_ON_EMPTY", &config.RetryOnEmpty)
+
+	if v := os.Getenv("TIE_BREAKER_PROVIDER"); v != "" {
+		config.TieBreakerProvider = v
+	}
+	if v := os.Getenv("ERROR_BODY_SNIPPET_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.ErrorBodySnippetBytes = n
+		}
+	}
+	if v := os.Getenv("REQUIRED_FIELDS"); v != "" {
+		if fields, err := ParseAddressFields(v); err == nil {
+			config.RequiredFields = fields
+		} else {
+			log.Println("Ignorando REQUIRED_FIELDS:", err)
+		}
+	}
+	if v := os.Getenv("EMPTY_FIELDS"); v != "" {
+		if fields, err := ParseAddressFields(v); err == nil {
+			config.EmptyFields = fields
+		} else {
+			log.Println("Ignorando EMPTY_FIELDS:", err)
+		}
+	}
+	if v := os.Getenv("MIN_AGREEMENT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			config.MinAgreement = n
+		}
+	}
+
+	if v := os.Getenv("ALLOWED_CEP_RANGES"); v != "" {
+		if ranges, err := parseCEPRanges(strings.Split(v, ",")); err == nil {
+			config.AllowedCEPRanges = ranges
+		} else {
+			log.Println("Ignorando ALLOWED_CEP_RANGES:", err)
+		}
+	}
+
+	if v := os.Getenv("RETRY_ON_STATUS"); v != "" {
+		var statuses []int
+		for _, field := range strings.Split(v, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				log.Println("Ignorando RETRY_ON_STATUS:", err)
+				statuses = nil
+				break
+			}
+			statuses = append(statuses, status)
+		}
+		if statuses != nil {
+			config.RetryOnStatus = statuses
+		}
+	}
+
+	if v := os.Getenv("STANDBY_PROVIDERS"); v != "" {
+		config.StandbyProviders = strings.Split(strings.ReplaceAll(v, " ", ""), ",")
+	}
+	if v := os.Getenv("STANDBY_ERROR_RATE"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil {
+			config.StandbyErrorRate = r
+		}
+	}
+	if v := os.Getenv("DEPRECATED_PROVIDERS"); v != "" {
+		config.DeprecatedProviders = strings.Split(strings.ReplaceAll(v, " ", ""), ",")
+	}
+	if headers := os.Getenv("CAPTURE_HEADERS"); headers != "" {
+		config.CaptureHeaders = nil
+		for _, h := range strings.Split(headers, ",") {
+			config.CaptureHeaders = append(config.CaptureHeaders, strings.TrimSpace(h))
+		}
+	}
+}
+
+func parseCEPRanges(values []string) ([]CEPRange, error) {
+	ranges := make([]CEPRange, 0, len(values))
+	for _, v := range values {
+		r, err := ParseCEPRange(v)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func envBool(name string, target *bool) {
+	if v, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		*target = v
+	}
+}
+
+// parseTimeouts interpreta uma lista "chave=prazo" separada por vírgulas (ex.: "689=3s,69=2s"
+// ou "ViaCEP=3s,BrasilAPI=500ms")
+func parseTimeouts(v string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("par chave=prazo inválido: %q", pair)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		timeouts[key] = timeout
+	}
+	return timeouts, nil
+}
+
+// parseRetries interpreta pares API=novas tentativas (ex.: "BrasilAPI=3,ViaCEP=0")
+func parseRetries(v string) (map[string]int, error) {
+	retries := make(map[string]int)
+	for _, pair := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("par chave=tentativas inválido: %q", pair)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		retries[key] = n
+	}
+	return retries, nil
+}
+
+// parseDurations interpreta uma lista de durações (ex.: "300ms", "1s")
+func parseDurations(values []string) ([]time.Duration, error) {
+	durations := make([]time.Duration, 0, len(values))
+	for _, v := range values {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
+
+// Estrutura com a configuração efetiva de uma API, para inspeção
+type ProviderDescription struct {
+	Name          string             `json:"name"`
+	URL           string             `json:"url"`
+	Enabled       bool               `json:"enabled"`
+	RetryOnStatus []int              `json:"retry_on_status"`
+	MaxRetries    int                `json:"max_retries"` // efetivo: ProviderMaxRetries, MaxRetries ou 0 com DisableRetries
+	Endpoints     []ProviderEndpoint `json:"endpoints,omitempty"`
+}
+
+// Estrutura com a configuração efetiva (após arquivo e variáveis de ambiente), sem segredos
+type ConfigDescription struct {
+	Providers              []ProviderDescription `json:"providers"`
+	Timeout                string                `json:"timeout"`
+	AttemptTimeout         string                `json:"attempt_timeout"`
+	RetryTimeoutMultiplier float64               `json:"retry_timeout_multiplier"`
+	TimeoutLadder          []string              `json:"timeout_ladder,omitempty"`
+	MaxRetries             int                   `json:"max_retries"`
+	DisableRetries         bool                  `json:"disable_retries"`
+	MinAgreement           int                   `json:"min_agreement"`
+	DNSCooldown            string                `json:"dns_cooldown"`
+	CacheTTL               string                `json:"cache_ttl"`
+	DatasetPath            string                `json:"dataset_path,omitempty"`
+	CassetteMode           string                `json:"cassette_mode,omitempty"`
+	AuditLogger            bool                  `json:"audit_logger"`
+	SignRequest            bool                  `json:"sign_request"`
+}
+
+// Describe resume a configuração efetiva para conferência em produção. Credenciais e
+// parâmetros de consulta das URLs são ocultados.
+func (c Config) Describe() ConfigDescription {
+	d := ConfigDescription{
+		Timeout:                c.Timeout.String(),
+		AttemptTimeout:         c.AttemptTimeout.String(),
+		RetryTimeoutMultiplier: c.RetryTimeoutMultiplier,
+		MaxRetries:             c.MaxRetries,
+		DisableRetries:         c.DisableRetries,
+		MinAgreement:           c.MinAgreement,
+		DNSCooldown:            c.DNSCooldown.String(),
+		CacheTTL:               c.CacheTTL.String(),
+		DatasetPath:            c.DatasetPath,
+		CassetteMode:           c.CassetteMode,
+		AuditLogger:            c.AuditLogger != nil,
+		SignRequest:            c.SignRequest != nil,
+	}
+	for _, timeout := range c.TimeoutLadder {
+		d.TimeoutLadder = append(d.TimeoutLadder, timeout.String())
+	}
+
+	bases := map[string]string{
+		"BrasilAPI": c.BrasilAPIURL,
+		"ViaCEP":    c.ViaCEPURL,
+		"OpenCEP":   c.OpenCEPURL,
+	}
+	for _, name := range providerNames() {
+		d.Providers = append(d.Providers, ProviderDescription{
+			Name:          name,
+			URL:           redactURL(bases[name]),
+			Enabled:       !slices.Contains(c.ExcludedProviders, name),
+			RetryOnStatus: retryStatuses(name, c),
+			MaxRetries:    maxAttempts(name, c) - 1,
+		})
+		for _, e := range c.ProviderEndpoints[name] {
+			last := &d.Providers[len(d.Providers)-1]
+			last.Endpoints = append(last.Endpoints, ProviderEndpoint{URL: redactURL(e.URL), Weight: e.Weight})
+		}
+	}
+	return d
+}
+
+// redactURL oculta a senha e os valores da query (onde costumam ficar tokens) de uma URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "REDACTED"
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, "REDACTED")
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
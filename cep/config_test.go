@@ -0,0 +1,47 @@
+package cep
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigRejectsInvalidEnv(t *testing.T) {
+	tests := []struct {
+		env, value string
+	}{
+		{"LOOKUP_STRATEGY", "mais-rapida"},
+		{"IP_FAMILY", "ipv5"},
+		{"HTTP2", "talvez"},
+		{"CASSETTE_MODE", "gravar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			if _, err := LoadConfig(); err == nil {
+				t.Errorf("LoadConfig aceitou %s=%q", tt.env, tt.value)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(); err != nil {
+		t.Errorf("LoadConfig sem variáveis: %v", err)
+	}
+}
+
+func TestDescribeRetriesAndCache(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRetries = 4
+	config.ProviderMaxRetries = map[string]int{"ViaCEP": 1}
+	config.CacheTTL = time.Minute
+
+	d := config.Describe()
+	if d.MaxRetries != 4 || d.CacheTTL != "1m0s" {
+		t.Errorf("MaxRetries = %d, CacheTTL = %q; esperava 4 e 1m0s", d.MaxRetries, d.CacheTTL)
+	}
+	want := map[string]int{"BrasilAPI": 4, "ViaCEP": 1, "OpenCEP": 4}
+	for _, p := range d.Providers {
+		if p.MaxRetries != want[p.Name] {
+			t.Errorf("%s: MaxRetries = %d, esperava %d", p.Name, p.MaxRetries, want[p.Name])
+		}
+	}
+}
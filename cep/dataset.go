@@ -0,0 +1,142 @@
+package cep
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Estrutura de uma entrada do dataset local (JSON)
+type datasetEntry struct {
+	CEP        string `json:"cep"`
+	Logradouro string `json:"logradouro"`
+	Bairro     string `json:"bairro"`
+	Cidade     string `json:"cidade"`
+	UF         string `json:"uf"`
+}
+
+// loadDataset lê um arquivo JSON (lista de objetos) ou CSV (cep,logradouro,bairro,cidade,uf)
+// e devolve os endereços indexados pelo CEP sem formatação.
+func loadDataset(path string) (map[string]Address, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dataset := make(map[string]Address)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var entries []datasetEntry
+		if err := json.NewDecoder(file).Decode(&entries); err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			dataset[datasetKey(e.CEP)] = Address{
+				CEP:        e.CEP,
+				Logradouro: e.Logradouro,
+				Bairro:     e.Bairro,
+				Cidade:     e.Cidade,
+				UF:         e.UF,
+			}
+		}
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = 5
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			dataset[datasetKey(r[0])] = Address{
+				CEP:        r[0],
+				Logradouro: r[1],
+				Bairro:     r[2],
+				Cidade:     r[3],
+				UF:         r[4],
+			}
+		}
+	default:
+		return nil, fmt.Errorf("formato de dataset não suportado: %s", path)
+	}
+
+	return dataset, nil
+}
+
+func datasetKey(cep string) string {
+	return stripCEPFormatting(cep)
+}
+
+// Datasets já lidos, por caminho; o arquivo é lido na primeira busca que precisa dele e
+// alterações posteriores só valem após reiniciar o processo
+var datasets = struct {
+	sync.Mutex
+	byPath map[string]map[string]Address
+}{byPath: make(map[string]map[string]Address)}
+
+// cachedDataset devolve o dataset do caminho, lendo o arquivo só na primeira vez; falhas na
+// leitura não são guardadas, para que a próxima busca tente de novo
+func cachedDataset(path string) (map[string]Address, error) {
+	datasets.Lock()
+	defer datasets.Unlock()
+	if dataset, ok := datasets.byPath[path]; ok {
+		return dataset, nil
+	}
+	dataset, err := loadDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	datasets.byPath[path] = dataset
+	return dataset, nil
+}
+
+func fetchFromDataset(path, cep string) (Address, error) {
+	dataset, err := cachedDataset(path)
+	if err != nil {
+		return Address{}, &DetailedError{API: "Local", Message: err.Error()}
+	}
+
+	address, ok := dataset[datasetKey(cep)]
+	if !ok {
+		return Address{}, &DetailedError{API: "Local", Message: "CEP não encontrado no dataset"}
+	}
+	return address, nil
+}
+
+// FileProvider é uma fonte que consulta um dataset local (JSON ou CSV, no formato de
+// DatasetPath). Registrada com RegisterProvider, corre ao lado das APIs e, pelo nome, pode ser
+// priorizada, excluída ou posta em uma camada, ao contrário de DatasetPath, que só vale quando
+// nenhuma API responde.
+type FileProvider struct {
+	name string
+	path string
+}
+
+// NewFileProvider cria a fonte com o nome usado nas opções e em Source e o caminho do dataset
+func NewFileProvider(name, path string) *FileProvider {
+	return &FileProvider{name: name, path: path}
+}
+
+func (p *FileProvider) Name() string { return p.name }
+
+// Lookup procura o CEP no dataset, lido do disco só na primeira busca
+func (p *FileProvider) Lookup(ctx context.Context, cep string) (Address, error) {
+	if err := ctx.Err(); err != nil {
+		return Address{}, err
+	}
+	dataset, err := cachedDataset(p.path)
+	if err != nil {
+		return Address{}, err
+	}
+	address, ok := dataset[datasetKey(cep)]
+	if !ok {
+		return Address{}, fmt.Errorf("%w (dataset %s)", ErrCEPNotFound, p.path)
+	}
+	return address, nil
+}
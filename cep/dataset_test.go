@@ -0,0 +1,60 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetchFromDataset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ceps.csv")
+	if err := os.WriteFile(path, []byte("01.153-000,Rua Vitorino Carmilo,Barra Funda,São Paulo,SP\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := fetchFromDataset(path, "01153000")
+	if err != nil {
+		t.Fatalf("CEP formatado no dataset não encontrado: %v", err)
+	}
+	if address.Logradouro != "Rua Vitorino Carmilo" {
+		t.Errorf("endereço = %+v", address)
+	}
+
+	// Já carregado, o dataset não é lido de novo
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetchFromDataset(path, "01153-000"); err != nil {
+		t.Errorf("segunda busca releu o arquivo: %v", err)
+	}
+}
+
+func TestFileProviderCanBePrioritized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ceps.json")
+	if err := os.WriteFile(path, []byte(`[{"cep":"01153-000","logradouro":"Rua Vitorino Carmilo","bairro":"Barra Funda","cidade":"São Paulo","uf":"SP"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registerForTest(t, NewFileProvider("Arquivo", path))
+	slow := stubAPI(t, http.StatusOK, brasilAPIBody, time.Second)
+
+	start := time.Now()
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", testConfig(slow, slow), WithProviderPriority("Arquivo"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Source != "Arquivo" || response.Result.Logradouro != "Rua Vitorino Carmilo" {
+		t.Errorf("resposta = %s %+v, esperava o endereço do dataset", response.Source, response.Result)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("busca levou %v, esperava o dataset antes das APIs", elapsed)
+	}
+
+	_, err = FetchFastestAPIResponse(context.Background(), "99999999", testConfig(slow, slow), WithProviderPriority("Arquivo"), WithExcludeProviders("BrasilAPI", "ViaCEP"))
+	if !errors.Is(err, ErrCEPNotFound) {
+		t.Errorf("erro = %v, esperava ErrCEPNotFound para CEP fora do dataset", err)
+	}
+}
@@ -0,0 +1,122 @@
+package cep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// providerDecoder decodifica o corpo da resposta de uma API em um endereço. O rótulo devolvido
+// substitui o nome da API como fonte do resultado (vazio mantém o nome).
+type providerDecoder func(body []byte, config Config) (address Address, label string, err error)
+
+// Decodificação de cada API; cada uma conhece apenas a própria estrutura de resposta
+var providerDecoders = map[string]providerDecoder{
+	"BrasilAPI": decodeBrasilAPI,
+	"ViaCEP":    decodeViaCEP,
+	"OpenCEP":   decodeOpenCEP,
+}
+
+func decodeBrasilAPI(body []byte, config Config) (Address, string, error) {
+	var response BrasilAPIResponse
+	if err := decodeResponse(body, &response, config); err != nil {
+		return Address{}, "", err
+	}
+
+	var label string
+	if config.BrasilAPIServiceSource && response.Service != "" {
+		label = "BrasilAPI/" + response.Service
+	}
+	return Address{
+		CEP:        response.CEP,
+		Logradouro: response.Street,
+		Bairro:     response.Neighborhood,
+		Cidade:     response.City,
+		UF:         response.State,
+		Latitude:   response.Location.Coordinates.Latitude.String(),
+		Longitude:  response.Location.Coordinates.Longitude.String(),
+		Extra:      extraFields(body, response, config),
+	}, label, nil
+}
+
+func decodeViaCEP(body []byte, config Config) (Address, string, error) {
+	var response ViaCEPResponse
+	if err := decodeResponse(body, &response, config); err != nil {
+		return Address{}, "", err
+	}
+	if viaCEPNotFound(response.Erro) {
+		return Address{}, "", ErrCEPNotFound
+	}
+	return Address{
+		CEP:        response.CEP,
+		Logradouro: response.Logradouro,
+		Bairro:     response.Bairro,
+		Cidade:     response.Localidade,
+		UF:         response.UF,
+		Extra:      extraFields(body, response, config),
+	}, "", nil
+}
+
+// viaCEPNotFound interpreta o campo erro da ViaCEP, que chega como true ou "true"
+func viaCEPNotFound(erro any) bool {
+	switch v := erro.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func decodeOpenCEP(body []byte, config Config) (Address, string, error) {
+	var response OpenCEPResponse
+	if err := decodeResponse(body, &response, config); err != nil {
+		return Address{}, "", err
+	}
+	return Address{
+		CEP:        response.CEP,
+		Logradouro: response.Logradouro,
+		Bairro:     response.Bairro,
+		Cidade:     response.Localidade,
+		UF:         response.UF,
+		Extra:      extraFields(body, response, config),
+	}, "", nil
+}
+
+// extraFields devolve, com Config.CaptureExtraFields, os campos simples da resposta que não
+// existem na estrutura da API ou que nela têm extra:"true" (ex.: gia, siafi e ddd da ViaCEP);
+// sem a opção, devolve nil
+func extraFields(body []byte, response any, config Config) map[string]string {
+	if !config.CaptureExtraFields {
+		return nil
+	}
+
+	// UseNumber mantém números como vieram (ex.: 3550308, não 3.550308e+06)
+	var raw map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(response)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		known[name] = field.Tag.Get("extra") != "true"
+	}
+
+	extra := make(map[string]string)
+	for key, value := range raw {
+		switch value.(type) {
+		case string, json.Number, bool:
+			if !known[key] {
+				extra[key] = fmt.Sprint(value)
+			}
+		}
+	}
+	return extra
+}
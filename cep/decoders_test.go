@@ -0,0 +1,59 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestDecodeViaCEPDocumentedFields(t *testing.T) {
+	config := DefaultConfig()
+	config.DisallowUnknownFields = true
+	config.CaptureExtraFields = true
+
+	address, _, err := decodeViaCEP([]byte(viaCEPBody), config)
+	if err != nil {
+		t.Fatalf("resposta documentada da ViaCEP rejeitada: %v", err)
+	}
+	if address.Cidade != "São Paulo" || address.Extra["gia"] != "1004" || address.Extra["ddd"] != "11" {
+		t.Errorf("endereço = %+v, esperava cidade e campos extras gia e ddd", address)
+	}
+
+	_, _, err = decodeViaCEP([]byte(`{"cep":"01153-000","novo_campo":"x"}`), config)
+	if !errors.Is(err, ErrUnknownField) {
+		t.Errorf("erro = %v, esperava ErrUnknownField", err)
+	}
+	if shouldRetry(err, "ViaCEP", config) {
+		t.Error("ErrUnknownField não deve gerar nova tentativa")
+	}
+}
+
+func TestDecodeViaCEPNotFound(t *testing.T) {
+	config := DefaultConfig()
+	config.DisallowUnknownFields = true
+
+	for _, body := range []string{`{"erro": true}`, `{"erro": "true"}`} {
+		if _, _, err := decodeViaCEP([]byte(body), config); !errors.Is(err, ErrCEPNotFound) {
+			t.Errorf("%s: erro = %v, esperava ErrCEPNotFound", body, err)
+		}
+	}
+	for _, body := range []string{`{"erro": false}`, `{"erro": "false"}`, viaCEPBody} {
+		if _, _, err := decodeViaCEP([]byte(body), config); err != nil {
+			t.Errorf("%s: erro inesperado na decodificação: %v", body, err)
+		}
+	}
+
+	// Pela busca, o CEP inexistente não é repetido e vira um DetailedError da ViaCEP
+	viaCEP, hits := countingAPI(t, http.StatusOK, `{"erro": "true"}`)
+	lookupConfig := testConfig(viaCEP, viaCEP)
+	lookupConfig.ExcludedProviders = []string{"BrasilAPI", "OpenCEP"}
+	_, err := FetchFastestAPIResponse(context.Background(), "01153000", lookupConfig)
+	var detailed *DetailedError
+	if !errors.As(err, &detailed) || detailed.API != "ViaCEP" || !errors.Is(err, ErrCEPNotFound) {
+		t.Errorf("erro = %v, esperava DetailedError da ViaCEP com ErrCEPNotFound", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("requisições = %d, esperava 1", hits.Load())
+	}
+}
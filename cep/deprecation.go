@@ -0,0 +1,29 @@
+package cep
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Intervalo mínimo entre dois avisos de uso de uma mesma API obsoleta
+const deprecationWarningInterval = time.Hour
+
+// Momento do último aviso de cada API obsoleta
+var deprecationWarnings = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: make(map[string]time.Time)}
+
+// warnDeprecated avisa no log que a API obsoleta participou de uma busca, no máximo uma vez
+// por deprecationWarningInterval
+func warnDeprecated(source string) {
+	deprecationWarnings.Lock()
+	defer deprecationWarnings.Unlock()
+
+	if last, ok := deprecationWarnings.last[source]; ok && time.Since(last) < deprecationWarningInterval {
+		return
+	}
+	deprecationWarnings.last[source] = time.Now()
+	log.Printf("Aviso: a API %s está marcada como obsoleta e será removida; ela continua participando das buscas", source)
+}
@@ -0,0 +1,94 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Estrutura com a comparação de um campo do endereço entre duas APIs
+type FieldDiff struct {
+	Field  string
+	First  string
+	Second string
+	Equal  bool
+}
+
+// Estrutura com a comparação campo a campo das respostas de duas APIs para um CEP
+type AddressDiff struct {
+	CEP    string
+	First  string // nome da primeira API
+	Second string // nome da segunda API
+	Fields []FieldDiff
+}
+
+// Equal informa se as duas APIs concordaram em todos os campos
+func (d AddressDiff) Equal() bool {
+	for _, f := range d.Fields {
+		if !f.Equal {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff consulta as duas APIs informadas em paralelo e compara os campos das respostas.
+// O CEP é comparado sem formatação; os demais campos ignoram espaços nas pontas e caixa.
+func Diff(ctx context.Context, cep string, config Config, first, second string) (AddressDiff, error) {
+	cep, err := ValidateCEP(cep)
+	if err != nil {
+		return AddressDiff{}, err
+	}
+	if first == second {
+		return AddressDiff{}, fmt.Errorf("é preciso comparar duas APIs diferentes: %s", first)
+	}
+
+	apis, err := apiURLs(cep, config)
+	if err != nil {
+		return AddressDiff{}, err
+	}
+	for _, name := range []string{first, second} {
+		if _, ok := apis[name]; !ok {
+			return AddressDiff{}, fmt.Errorf("API desconhecida: %s", name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		response APIResponse
+		err      error
+	}
+	firstCh := make(chan outcome, 1)
+	secondCh := make(chan outcome, 1)
+	for name, ch := range map[string]chan outcome{first: firstCh, second: secondCh} {
+		go func(url, name string, ch chan outcome) {
+			response, err := fetchAPIWithRetry(ctx, cep, url, name, maxAttempts(name, config), config)
+			ch <- outcome{response: response, err: err}
+		}(apis[name], name, ch)
+	}
+
+	a, b := <-firstCh, <-secondCh
+	if err := errors.Join(a.err, b.err); err != nil {
+		return AddressDiff{}, err
+	}
+
+	x, y := a.response.Result, b.response.Result
+	diff := AddressDiff{CEP: cep, First: first, Second: second}
+	add := func(field, v1, v2 string, equal bool) {
+		diff.Fields = append(diff.Fields, FieldDiff{Field: field, First: v1, Second: v2, Equal: equal})
+	}
+	sameText := func(v1, v2 string) bool {
+		return strings.EqualFold(strings.TrimSpace(v1), strings.TrimSpace(v2))
+	}
+
+	add("CEP", x.CEP, y.CEP, stripCEPFormatting(x.CEP) == stripCEPFormatting(y.CEP))
+	add("Logradouro", x.Logradouro, y.Logradouro, sameText(x.Logradouro, y.Logradouro))
+	add("Bairro", x.Bairro, y.Bairro, sameText(x.Bairro, y.Bairro))
+	add("Cidade", x.Cidade, y.Cidade, sameText(x.Cidade, y.Cidade))
+	add("UF", x.UF, y.UF, sameText(x.UF, y.UF))
+
+	return diff, nil
+}
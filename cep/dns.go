@@ -0,0 +1,109 @@
+package cep
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hosts cujo DNS falhou recentemente. A espera vale para o host, não para o nome da API:
+// Configs que apontam o mesmo provedor para outra URL não herdam a falha.
+var dnsCooldowns = struct {
+	sync.Mutex
+	until map[string]time.Time
+}{until: make(map[string]time.Time)}
+
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// cooldownHost devolve o host da URL da API, ou vazio para provedores sem URL
+func cooldownHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+func markDNSFailure(source, rawURL string, cooldown time.Duration) {
+	host := cooldownHost(rawURL)
+	if host == "" {
+		return
+	}
+	dnsCooldowns.Lock()
+	defer dnsCooldowns.Unlock()
+	dnsCooldowns.until[host] = time.Now().Add(cooldown)
+	log.Printf("Falha de DNS em %s (%s), removida da corrida por %v", source, host, cooldown)
+}
+
+func inDNSCooldown(rawURL string) bool {
+	return dnsCooldownRemaining(rawURL) > 0
+}
+
+// dnsCooldownRemaining devolve quanto falta para o host da URL voltar à corrida, ou 0 fora da espera
+func dnsCooldownRemaining(rawURL string) time.Duration {
+	host := cooldownHost(rawURL)
+	if host == "" {
+		return 0
+	}
+	dnsCooldowns.Lock()
+	defer dnsCooldowns.Unlock()
+	until, ok := dnsCooldowns.until[host]
+	if !ok {
+		return 0
+	}
+	remaining := time.Until(until)
+	if remaining <= 0 {
+		delete(dnsCooldowns.until, host)
+		return 0
+	}
+	return remaining
+}
+
+// ErrAllProvidersDown indica que todas as APIs da busca estão em espera por falha de DNS
+// (apenas com Config.FailFastWhenAllDown)
+var ErrAllProvidersDown = errors.New("todas as APIs estão em espera por falha de DNS")
+
+// checkAllProvidersDown devolve ErrAllProvidersDown, com a espera restante de cada API, quando
+// nenhuma das APIs da busca está disponível
+func checkAllProvidersDown(cep string, config Config) error {
+	apis, err := selectAPIs(cep, config)
+	if err != nil || len(apis) == 0 {
+		// A própria busca informa o erro
+		return nil
+	}
+
+	states := make([]string, 0, len(apis))
+	for source, url := range apis {
+		remaining := dnsCooldownRemaining(url)
+		if remaining == 0 {
+			return nil
+		}
+		states = append(states, fmt.Sprintf("%s volta em %v", source, remaining.Round(100*time.Millisecond)))
+	}
+	slices.Sort(states)
+	return fmt.Errorf("%w: %s", ErrAllProvidersDown, strings.Join(states, ", "))
+}
+
+// activeAPIs remove as APIs em espera por falha de DNS. Se todas estiverem em
+// espera, mantém a lista completa para não desistir da busca sem tentar.
+func activeAPIs(apis map[string]string) map[string]string {
+	active := make(map[string]string, len(apis))
+	for source, url := range apis {
+		if !inDNSCooldown(url) {
+			active[source] = url
+		}
+	}
+	if len(active) == 0 {
+		return apis
+	}
+	return active
+}
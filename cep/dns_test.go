@@ -0,0 +1,28 @@
+package cep
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDNSCooldownIsPerHost(t *testing.T) {
+	t.Cleanup(func() {
+		dnsCooldowns.Lock()
+		delete(dnsCooldowns.until, "down.example")
+		dnsCooldowns.Unlock()
+	})
+	markDNSFailure("BrasilAPI", "http://down.example/api/cep/v1/01153000", time.Minute)
+
+	// Outra Config aponta a BrasilAPI para um host saudável; ela não herda a espera
+	apis := map[string]string{
+		"BrasilAPI": "http://up.example/api/cep/v1/01153000",
+		"ViaCEP":    "http://down.example/ws/01153000/json/",
+	}
+	active := activeAPIs(apis)
+	if _, ok := active["BrasilAPI"]; !ok {
+		t.Error("BrasilAPI em outro host foi removida da corrida")
+	}
+	if _, ok := active["ViaCEP"]; ok {
+		t.Error("ViaCEP no host com falha de DNS continuou na corrida")
+	}
+}
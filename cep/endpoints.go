@@ -0,0 +1,50 @@
+package cep
+
+import "sync"
+
+// Estrutura para um espelho de uma API; espelhos com mais peso recebem mais requisições
+type ProviderEndpoint struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"` // peso <= 0 conta como 1
+}
+
+// Pesos correntes do round-robin ponderado, por API e URL do espelho
+var endpointWeights = struct {
+	sync.Mutex
+	current map[string]map[string]int
+}{current: make(map[string]map[string]int)}
+
+// providerPath devolve os segmentos acrescentados à URL base de cada API
+func providerPath(source, cep string) []string {
+	if source == "ViaCEP" {
+		return []string{cep, "json"}
+	}
+	return []string{cep}
+}
+
+// nextEndpoint escolhe o próximo espelho da API por round-robin ponderado suave. O espelho
+// skip (o que acabou de falhar) fica de fora quando há alternativa.
+func nextEndpoint(source string, endpoints []ProviderEndpoint, skip string) string {
+	endpointWeights.Lock()
+	defer endpointWeights.Unlock()
+
+	current, ok := endpointWeights.current[source]
+	if !ok {
+		current = make(map[string]int)
+		endpointWeights.current[source] = current
+	}
+
+	best, total := "", 0
+	for _, e := range endpoints {
+		if e.URL == skip && len(endpoints) > 1 {
+			continue
+		}
+		current[e.URL] += max(e.Weight, 1)
+		total += max(e.Weight, 1)
+		if best == "" || current[e.URL] > current[best] {
+			best = e.URL
+		}
+	}
+	current[best] -= total
+	return best
+}
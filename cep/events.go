@@ -0,0 +1,81 @@
+package cep
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Estrutura de um evento publicado ao fim de cada busca
+type LookupEvent struct {
+	CEP       string
+	Result    Address
+	Source    string
+	Attempt   int // tentativa que obteve a resposta (ver APIResponse.Attempt)
+	Duration  time.Duration
+	Timestamp time.Time
+	Err       error
+}
+
+// EventSink recebe um evento de cada busca feita por FetchFastestAPI. Implementações para
+// filas (Kafka, NATS etc.) ficam a cargo da aplicação, que já tem o cliente da fila, ex.:
+//
+//	type natsSink struct{ conn *nats.Conn }
+//
+//	func (s natsSink) Publish(ctx context.Context, e LookupEvent) error {
+//		data, err := json.Marshal(e)
+//		if err != nil {
+//			return err
+//		}
+//		return s.conn.Publish("cep.lookups", data)
+//	}
+type EventSink interface {
+	Publish(ctx context.Context, event LookupEvent) error
+}
+
+// NoopEventSink descarta os eventos; é o comportamento quando Config.EventSink é nil
+type NoopEventSink struct{}
+
+func (NoopEventSink) Publish(context.Context, LookupEvent) error { return nil }
+
+// Publicações simultâneas no EventSink; com todas ocupadas o evento é descartado e contado
+// em dropped_events, para que um EventSink lento não acumule goroutines sem fim
+const maxConcurrentEvents = 64
+
+var eventSlots = make(chan struct{}, maxConcurrentEvents)
+
+// publishEvent envia o evento em segundo plano, sem atrasar o retorno da busca; falhas
+// apenas são logadas
+func publishEvent(ctx context.Context, config Config, cep string, response APIResponse, err error, start time.Time) {
+	if config.EventSink == nil {
+		return
+	}
+
+	event := LookupEvent{
+		CEP:       cep,
+		Result:    response.Result,
+		Source:    response.Source,
+		Attempt:   response.Attempt,
+		Duration:  time.Since(start),
+		Timestamp: time.Now(),
+		Err:       err,
+	}
+	select {
+	case eventSlots <- struct{}{}:
+	default:
+		metrics.droppedEvents.Add(1)
+		log.Printf("Evento do CEP %s descartado: %d publicações no EventSink em andamento", cep, maxConcurrentEvents)
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() { <-eventSlots }()
+
+		ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+		if publishErr := config.EventSink.Publish(ctx, event); publishErr != nil {
+			log.Printf("Erro ao publicar evento do CEP %s: %v", cep, publishErr)
+		}
+	}()
+}
@@ -0,0 +1,43 @@
+package cep
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingEventSink segura cada publicação até release ser fechado
+type blockingEventSink struct {
+	release   chan struct{}
+	published atomic.Int32
+}
+
+func (s *blockingEventSink) Publish(ctx context.Context, event LookupEvent) error {
+	<-s.release
+	s.published.Add(1)
+	return nil
+}
+
+func TestPublishEventDropsWhenFull(t *testing.T) {
+	sink := &blockingEventSink{release: make(chan struct{})}
+	config := DefaultConfig()
+	config.EventSink = sink
+
+	dropped := metrics.droppedEvents.Value()
+	for range maxConcurrentEvents + 5 {
+		publishEvent(context.Background(), config, "01153000", APIResponse{}, nil, time.Now())
+	}
+	if got := metrics.droppedEvents.Value() - dropped; got != 5 {
+		t.Errorf("eventos descartados = %d, esperava 5", got)
+	}
+
+	close(sink.release)
+	deadline := time.Now().Add(time.Second)
+	for len(eventSlots) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := sink.published.Load(); got != maxConcurrentEvents {
+		t.Errorf("eventos publicados = %d, esperava %d", got, maxConcurrentEvents)
+	}
+}
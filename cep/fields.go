@@ -0,0 +1,95 @@
+package cep
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AddressField identifica campos do endereço; valores podem ser combinados com |
+type AddressField uint8
+
+const (
+	FieldCEP AddressField = 1 << iota
+	FieldLogradouro
+	FieldBairro
+	FieldCidade
+	FieldUF
+)
+
+var addressFieldNames = []struct {
+	field AddressField
+	name  string
+}{
+	{FieldCEP, "cep"},
+	{FieldLogradouro, "logradouro"},
+	{FieldBairro, "bairro"},
+	{FieldCidade, "cidade"},
+	{FieldUF, "uf"},
+}
+
+// ErrMissingFields indica uma resposta sem algum dos campos de Config.RequiredFields
+var ErrMissingFields = errors.New("resposta sem campos obrigatórios")
+
+func (f AddressField) String() string {
+	var names []string
+	for _, n := range addressFieldNames {
+		if f&n.field != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseAddressFields interpreta uma lista de nomes de campos (ex.: "cidade,uf")
+func ParseAddressFields(s string) (AddressField, error) {
+	var fields AddressField
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, n := range addressFieldNames {
+			if n.name == name {
+				fields |= n.field
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("campo de endereço desconhecido: %q", name)
+		}
+	}
+	return fields, nil
+}
+
+// ErrEmptyResponse indica uma resposta de sucesso sem dados, repetida quando Config.RetryOnEmpty
+var ErrEmptyResponse = errors.New("resposta vazia")
+
+// Campos considerados por isEmptyAddress quando Config.EmptyFields é 0; o CEP fica de fora
+// porque as APIs costumam apenas repeti-lo
+const defaultEmptyFields = FieldLogradouro | FieldBairro | FieldCidade | FieldUF
+
+// isEmptyAddress informa se todos os campos de fields estão vazios no endereço
+func isEmptyAddress(address Address, fields AddressField) bool {
+	if fields == 0 {
+		fields = defaultEmptyFields
+	}
+	return missingFields(address, fields) == fields
+}
+
+// missingFields devolve os campos de required que estão vazios no endereço
+func missingFields(address Address, required AddressField) AddressField {
+	values := map[AddressField]string{
+		FieldCEP:        address.CEP,
+		FieldLogradouro: address.Logradouro,
+		FieldBairro:     address.Bairro,
+		FieldCidade:     address.Cidade,
+		FieldUF:         address.UF,
+	}
+
+	var missing AddressField
+	for field, value := range values {
+		if required&field != 0 && strings.TrimSpace(value) == "" {
+			missing |= field
+		}
+	}
+	return missing
+}
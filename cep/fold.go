@@ -0,0 +1,48 @@
+package cep
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldText decompõe o texto (NFD), remove os acentos e recompõe o restante
+func foldText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
+
+// ASCIIFold remove os acentos dos campos de texto do endereço ("São Paulo" → "Sao Paulo"),
+// para sistemas que não aceitam caracteres acentuados
+func ASCIIFold(a Address) Address {
+	a.Logradouro = foldText(a.Logradouro)
+	a.Bairro = foldText(a.Bairro)
+	a.Cidade = foldText(a.Cidade)
+	a.UF = foldText(a.UF)
+	return a
+}
+
+// TrimFields remove espaços nas pontas de todos os campos do endereço
+func TrimFields(a Address) Address {
+	a.CEP = strings.TrimSpace(a.CEP)
+	a.Logradouro = strings.TrimSpace(a.Logradouro)
+	a.Bairro = strings.TrimSpace(a.Bairro)
+	a.Cidade = strings.TrimSpace(a.Cidade)
+	a.UF = strings.TrimSpace(a.UF)
+	return a
+}
+
+// FormatCEP deixa o CEP do endereço no formato 01153-000, quando ele tem 8 dígitos
+func FormatCEP(a Address) Address {
+	if cep := stripCEPFormatting(a.CEP); len(cep) == 8 && isDigits(cep) {
+		a.CEP = cep[:5] + "-" + cep[5:]
+	}
+	return a
+}
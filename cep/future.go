@@ -0,0 +1,32 @@
+package cep
+
+import "context"
+
+// Estrutura para uma busca em andamento iniciada por Client.LookupAsync
+type LookupFuture struct {
+	done     chan struct{}
+	response APIResponse
+	err      error
+}
+
+// LookupAsync inicia Lookup em segundo plano, com o cache e a Config do Client, e devolve um
+// LookupFuture para aguardar o resultado depois. Cancelar ctx cancela a busca.
+func (c *Client) LookupAsync(ctx context.Context, cep string, opts ...LookupOption) *LookupFuture {
+	f := &LookupFuture{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.response, f.err = c.Lookup(ctx, cep, opts...)
+	}()
+	return f
+}
+
+// Done é fechado quando a busca termina, permitindo usar o LookupFuture em um select
+func (f *LookupFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait bloqueia até o fim da busca e devolve a resposta e o erro, como Client.Lookup
+func (f *LookupFuture) Wait() (APIResponse, error) {
+	<-f.done
+	return f.response, f.err
+}
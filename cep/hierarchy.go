@@ -0,0 +1,42 @@
+package cep
+
+import "strings"
+
+// Estrutura do endereço em níveis, do estado até o logradouro (útil em telas de seleção)
+type HierarchicalAddress struct {
+	Regiao string // macrorregião do IBGE, deduzida da UF
+	Estado struct {
+		UF     string
+		Cidade struct {
+			Nome   string
+			Bairro struct {
+				Nome       string
+				Logradouro string
+			}
+		}
+	}
+	CEP string
+}
+
+// Macrorregiões do IBGE por UF
+var regionsByUF = map[string]string{
+	"AC": "Norte", "AM": "Norte", "AP": "Norte", "PA": "Norte", "RO": "Norte", "RR": "Norte", "TO": "Norte",
+	"AL": "Nordeste", "BA": "Nordeste", "CE": "Nordeste", "MA": "Nordeste", "PB": "Nordeste",
+	"PE": "Nordeste", "PI": "Nordeste", "RN": "Nordeste", "SE": "Nordeste",
+	"DF": "Centro-Oeste", "GO": "Centro-Oeste", "MS": "Centro-Oeste", "MT": "Centro-Oeste",
+	"ES": "Sudeste", "MG": "Sudeste", "RJ": "Sudeste", "SP": "Sudeste",
+	"PR": "Sul", "RS": "Sul", "SC": "Sul",
+}
+
+// Hierarchy converte o endereço em níveis; Address continua sendo o formato principal
+func (a Address) Hierarchy() HierarchicalAddress {
+	var h HierarchicalAddress
+	uf := strings.ToUpper(strings.TrimSpace(a.UF))
+	h.Regiao = regionsByUF[uf]
+	h.Estado.UF = uf
+	h.Estado.Cidade.Nome = a.Cidade
+	h.Estado.Cidade.Bairro.Nome = a.Bairro
+	h.Estado.Cidade.Bairro.Logradouro = a.Logradouro
+	h.CEP = a.CEP
+	return h
+}
@@ -0,0 +1,120 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Estrutura de uma tentativa feita a uma API durante a busca
+type AttemptRecord struct {
+	Provider string
+	URL      string
+	Attempt  int // começa em 1
+	Category string
+	Duration time.Duration
+	Err      error // nil quando a tentativa teve sucesso
+}
+
+// attemptHistory acumula as tentativas de todas as APIs de uma busca
+type attemptHistory struct {
+	mu      sync.Mutex
+	records []AttemptRecord
+}
+
+type attemptHistoryKey struct{}
+
+func withAttemptHistory(ctx context.Context) (context.Context, *attemptHistory) {
+	h := &attemptHistory{}
+	return context.WithValue(ctx, attemptHistoryKey{}, h), h
+}
+
+// recordAttempt registra a tentativa no histórico da busca, se houver um no contexto
+func recordAttempt(ctx context.Context, source, url string, attempt int, err error, duration time.Duration) {
+	h, ok := ctx.Value(attemptHistoryKey{}).(*attemptHistory)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, AttemptRecord{
+		Provider: source,
+		URL:      url,
+		Attempt:  attempt + 1,
+		Category: attemptCategory(err),
+		Duration: duration,
+		Err:      err,
+	})
+}
+
+// attemptCategory resume o resultado da tentativa para o histórico
+func attemptCategory(err error) string {
+	var detailed *DetailedError
+	switch {
+	case err == nil:
+		return "sucesso"
+	case errors.As(err, &detailed) && detailed.StatusCode != 0:
+		return fmt.Sprintf("http_%d", detailed.StatusCode)
+	case errors.Is(err, ErrEmptyResponse):
+		return "vazia"
+	case errors.Is(err, ErrCEPMismatch):
+		return "cep_divergente"
+	case errors.Is(err, ErrTooManyRedirects):
+		return "redirecionamento"
+	case errors.Is(err, ErrTruncatedResponse):
+		return "truncada"
+	case isDNSError(err):
+		return "dns"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "cancelada"
+	case errors.As(err, new(*net.OpError)):
+		return "conexão"
+	}
+	return "erro"
+}
+
+// log registra em uma única linha todas as tentativas, na ordem em que terminaram
+func (h *attemptHistory) log(cep string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	attempts := make([]string, 0, len(h.records))
+	for _, r := range h.records {
+		attempts = append(attempts, fmt.Sprintf("%s#%d=%s(%v)", r.Provider, r.Attempt, r.Category, r.Duration.Round(time.Millisecond)))
+	}
+	log.Printf("Histórico de tentativas do CEP %s: %s", cep, strings.Join(attempts, " "))
+}
+
+// snapshot copia as tentativas registradas até aqui
+func (h *attemptHistory) snapshot() []AttemptRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.records)
+}
+
+// Estrutura com o rastro detalhado de uma única busca, preenchida com WithTrace
+type LookupTrace struct {
+	CEP      string
+	Attempts []AttemptRecord // todas as tentativas, na ordem em que terminaram
+	Source   string
+	Duration time.Duration
+	Err      error
+}
+
+// WithTrace preenche trace com o rastro desta chamada (cada tentativa a cada API, com URL,
+// resultado e duração), sem ativar logs detalhados para as demais buscas
+func WithTrace(trace *LookupTrace) LookupOption {
+	return func(config *Config) error {
+		config.trace = trace
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package cep
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Valores de Config.Locale; vazio mantém as mensagens em português
+const (
+	LocalePortuguese = "pt"
+	LocaleEnglish    = "en"
+)
+
+// Estrutura com as mensagens de DetailedError em um idioma
+type errorMessages struct {
+	format       string            // API, mensagem e duração
+	tier         string            // número da camada e erro (ProviderTiers)
+	rung         string            // número do degrau, prazo e erro (TimeoutLadder)
+	translations map[string]string // início da mensagem em português → tradução
+}
+
+var localeMessages = map[string]errorMessages{
+	LocaleEnglish: {
+		format: "API %s error: %s (took %v)",
+		tier:   "tier %d: %w",
+		rung:   "rung %d (%v): %w",
+		translations: map[string]string{
+			ErrTimeout.Error():               "timeout",
+			ErrUnexpectedContentType.Error(): "unexpected Content-Type in response",
+			ErrUnknownField.Error():          "unknown field in response",
+			ErrCEPMismatch.Error():           "API answered with a different CEP",
+			ErrMissingFields.Error():         "response missing required fields",
+			ErrEmptyResponse.Error():         "empty response",
+			ErrTooManyRedirects.Error():      "too many redirects",
+			ErrTruncatedResponse.Error():     "truncated response",
+			ErrTooSlow.Error():               "result arrived after the maximum acceptable latency",
+			ErrCEPNotFound.Error():           "CEP not found",
+			"API desconhecida":               "unknown API",
+			"CEP não encontrado no dataset":  "CEP not found in dataset",
+		},
+	},
+}
+
+// formatDetailedError monta a mensagem de DetailedError no idioma; idiomas sem mensagens usam português
+func formatDetailedError(locale, api, message string, duration time.Duration) string {
+	messages, ok := localeMessages[locale]
+	if !ok {
+		return fmt.Sprintf("Erro na API %s: %s (durou %v)", api, message, duration)
+	}
+	// Vale o início mais longo, já que uns contêm outros (ex.: "CEP não encontrado")
+	matched := ""
+	for original := range messages.translations {
+		if strings.HasPrefix(message, original) && len(original) > len(matched) {
+			matched = original
+		}
+	}
+	if matched != "" {
+		message = messages.translations[matched] + message[len(matched):]
+	}
+	return fmt.Sprintf(messages.format, api, message, duration)
+}
+
+// tierError identifica no idioma a camada de ProviderTiers em que o erro ocorreu
+func tierError(locale string, tier int, err error) error {
+	if messages, ok := localeMessages[locale]; ok {
+		return fmt.Errorf(messages.tier, tier, err)
+	}
+	return fmt.Errorf("camada %d: %w", tier, err)
+}
+
+// rungError identifica no idioma o degrau de TimeoutLadder em que o erro ocorreu
+func rungError(locale string, rung int, timeout time.Duration, err error) error {
+	if messages, ok := localeMessages[locale]; ok {
+		return fmt.Errorf(messages.rung, rung, timeout, err)
+	}
+	return fmt.Errorf("degrau %d (%v): %w", rung, timeout, err)
+}
+
+// localizeError define o idioma de todos os DetailedError da árvore do erro, para os criados
+// sem Config (ex.: dataset local); a comparação com errors.Is/As não muda, pois usa os
+// sentinelas e não o texto. Erros que embrulham outros com fmt.Errorf já têm o texto montado,
+// por isso os DetailedError das APIs recebem o idioma ao serem criados.
+func localizeError(err error, locale string) {
+	if err == nil || locale == "" {
+		return
+	}
+	var detailed *DetailedError
+	if errors.As(err, &detailed) {
+		detailed.Locale = locale
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			localizeError(inner, locale)
+		}
+	case interface{ Unwrap() error }:
+		localizeError(e.Unwrap(), locale)
+	}
+}
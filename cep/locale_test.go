@@ -0,0 +1,45 @@
+package cep
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnglishErrorsThroughTiersAndLadder(t *testing.T) {
+	failing := stubAPI(t, http.StatusBadRequest, "", 0)
+
+	tests := []struct {
+		name      string
+		configure func(*Config)
+		prefix    string
+	}{
+		{"fallback", func(c *Config) { c.Strategy = StrategyFallback }, "tier 1: "},
+		{"provider tiers", func(c *Config) { c.ProviderTiers = [][]string{{"BrasilAPI"}, {"ViaCEP"}} }, "tier 1: "},
+		{"timeout ladder", func(c *Config) { c.TimeoutLadder = []time.Duration{time.Second} }, "rung 1 (1s): "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(failing, failing)
+			config.Locale = LocaleEnglish
+			config.DisableRetries = true
+			tt.configure(&config)
+
+			_, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+			if err == nil {
+				t.Fatal("esperava erro com as APIs falhando")
+			}
+			message := err.Error()
+			if !strings.HasPrefix(message, tt.prefix) || !strings.Contains(message, "API BrasilAPI error") {
+				t.Errorf("erro = %q, esperava o prefixo %q e mensagens em inglês", message, tt.prefix)
+			}
+			for _, portuguese := range []string{"Erro na API", "camada", "degrau"} {
+				if strings.Contains(message, portuguese) {
+					t.Errorf("erro = %q contém %q", message, portuguese)
+				}
+			}
+		})
+	}
+}
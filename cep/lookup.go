@@ -0,0 +1,1294 @@
+package cep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Config é passada por valor e pode ser reutilizada por várias goroutines ao mesmo tempo.
+// Todo estado compartilhado entre buscas é do pacote e seguro para uso concorrente: os clientes
+// HTTP (ver clientFor), a espera por falha de DNS de cada host, os pesos de ProviderEndpoints,
+// a saúde dos provedores, os avisos de DeprecatedProviders, as métricas do expvar e o dataset
+// carregado. Funções configuradas (como Transform) também devem ser seguras.
+type Config struct {
+	// Estratégia da busca: StrategyFastest (vazio, a primeira resposta vence), StrategyFallback
+	// (uma API de cada vez, na ordem) ou StrategyMerge (todas, combinando os campos)
+	Strategy string
+
+	BrasilAPIURL string
+	// Tentada antes da BrasilAPIURL (v1) por trazer coordenadas; vazio usa só a v1
+	BrasilAPIV2URL string
+	ViaCEPURL      string
+	OpenCEPURL     string
+	Timeout        time.Duration
+	DatasetPath    string // dataset local (JSON ou CSV) usado quando nenhuma API responde
+
+	// Publica os contadores das buscas em expvar sob este nome (ex.: "cep"), visíveis em
+	// /debug/vars quando a aplicação serve o http.DefaultServeMux; vazio não publica
+	ExpvarNamespace string
+
+	// Idioma das mensagens de DetailedError: LocaleEnglish ou vazio/LocalePortuguese (padrão)
+	Locale string
+
+	// Último recurso, após o dataset: devolve só CEP e UF, deduzida das faixas de CEP
+	// (ver BuildPartialFromCEP), com a fonte "Derivado"
+	DerivePartialAddress bool
+
+	// Rejeita respostas com campos desconhecidos (útil em canários para detectar mudanças de formato)
+	DisallowUnknownFields bool
+
+	// Guarda em Address.Extra os campos da resposta que não fazem parte do endereço
+	CaptureExtraFields bool
+
+	// Rejeita respostas sem Content-Type JSON antes de decodificá-las (ex.: páginas de erro HTML)
+	StrictContentType bool
+
+	// Remove os acentos dos campos do endereço (ver ASCIIFold), após Transforms
+	ASCIIFold bool
+
+	// Expande abreviações do logradouro e do bairro (ver ExpandAbbreviations) em cada resposta,
+	// antes das comparações entre APIs (MinAgreement, TieBreakerProvider)
+	ExpandAbbreviations bool
+
+	// Tradução opcional do CEP recebido (ex.: formatos internos com prefixo de região),
+	// aplicada uma vez por busca antes da validação; um erro encerra a busca
+	PreprocessCEP func(string) (string, error)
+
+	// Transformação opcional aplicada uma única vez ao endereço vencedor antes de retorná-lo
+	Transform func(Address) Address
+
+	// Transformações aplicadas em ordem, uma vez cada, logo após Transform
+	// (ex.: TrimFields, FormatCEP, ASCIIFold)
+	Transforms []func(Address) Address
+
+	// Inclui o serviço interno da BrasilAPI na fonte (ex.: "BrasilAPI/correios")
+	BrasilAPIServiceSource bool
+
+	// Apenas registra as requisições que seriam feitas, sem enviá-las
+	DryRun bool
+
+	// Cabeçalhos da resposta vencedora copiados para APIResponse.Headers (vazio desativa a captura)
+	CaptureHeaders []string
+
+	// Campos que a resposta precisa ter preenchidos para vencer (ex.: FieldCidade|FieldUF);
+	// 0 aceita qualquer resposta
+	RequiredFields AddressField
+
+	// Trata como falha repetível a resposta de sucesso com todos os campos de EmptyFields
+	// vazios (0 considera logradouro, bairro, cidade e UF)
+	RetryOnEmpty bool
+	EmptyFields  AddressField
+
+	// Número mínimo de APIs que precisam concordar em UF, cidade e logradouro (<= 1 mantém a corrida)
+	MinAgreement int
+
+	// Recebe um registro de cada busca; nil desativa a auditoria
+	AuditLogger AuditLogger
+
+	// Recebe um evento de cada busca, publicado sem bloquear a busca; nil desativa
+	EventSink EventSink
+
+	// Recebem o resultado de cada busca bem-sucedida, em paralelo e sem bloquear a busca
+	ResultSinks []ResultSink
+
+	// Rastro da busca, definido apenas por chamada com WithTrace
+	trace *LookupTrace
+
+	// API fora da corrida, consultada só quando as demais divergem em UF, cidade ou
+	// logradouro; sua resposta é preferida (ignorada quando MinAgreement > 1)
+	TieBreakerProvider string
+
+	// Resposta com CEP diferente do solicitado: vazio só registra no log, CEPMismatchReject
+	// a trata como erro e CEPMismatchRetry também tenta a API de novo
+	CEPMismatch string
+
+	// Usa o CEP solicitado em Address.CEP em vez do CEP devolvido pela API
+	// (equivale a CanonicalCEPSource = CanonicalCEPRequested)
+	UseRequestedCEP bool
+
+	// Origem de Address.CEP: vazio usa o CEP devolvido pela API vencedora, CanonicalCEPRequested
+	// usa o CEP solicitado e o nome de uma API usa o CEP devolvido por ela, ou o solicitado
+	// quando outra API venceu
+	CanonicalCEPSource string
+
+	// Tempo que uma API fica fora da corrida após falha de DNS (0 desativa). Se todas estiverem
+	// em espera, a busca tenta todas, ou falha na hora com ErrAllProvidersDown se
+	// FailFastWhenAllDown for definido
+	DNSCooldown         time.Duration
+	FailFastWhenAllDown bool
+
+	// Faixas de CEP atendidas; CEPs fora delas falham sem acessar a rede (vazio aceita todos)
+	AllowedCEPRanges []CEPRange
+
+	// Novas tentativas a cada API após a primeira (DefaultConfig usa 2; 0 faz uma única
+	// tentativa); DisableRetries faz uma única tentativa mesmo com ProviderMaxRetries, para
+	// caminhos em que a latência importa mais que a insistência
+	MaxRetries     int
+	DisableRetries bool
+
+	// Novas tentativas por API, no lugar de MaxRetries (0 faz uma única tentativa à API)
+	ProviderMaxRetries map[string]int
+
+	// Status HTTP que disparam nova tentativa (nil usa DefaultRetryOnStatus), com ajuste por API
+	RetryOnStatus         []int
+	ProviderRetryOnStatus map[string][]int
+
+	// Decide se a tentativa (a partir de 1) que falhou deve ser repetida, no lugar da
+	// classificação padrão; resp é nil em falhas de conexão e, quando presente, seu corpo já
+	// foi lido e fechado. O número de tentativas continua limitado
+	ShouldRetry func(attempt int, err error, resp *http.Response) bool
+
+	// APIs que não participam da busca (ver WithExcludeProviders)
+	ExcludedProviders []string
+
+	// APIs de reserva, fora da corrida até que alguma API ativa tenha taxa de erro recente
+	// de pelo menos StandbyErrorRate (0 usa 50%); voltam à reserva quando ela se recupera
+	StandbyProviders []string
+	StandbyErrorRate float64
+
+	// APIs em processo de remoção: continuam na busca, mas geram um aviso no log (no máximo
+	// um por hora por API)
+	DeprecatedProviders []string
+
+	// Espelhos de cada API; a cada tentativa um é escolhido por peso, e a nova tentativa
+	// após uma falha usa outro espelho (vazio usa só a URL base da API)
+	ProviderEndpoints map[string][]ProviderEndpoint
+
+	// Limite de cada tentativa (0 usa só o prazo total), multiplicado a cada nova tentativa
+	AttemptTimeout         time.Duration
+	RetryTimeoutMultiplier float64
+
+	// Limite de cada tentativa por API, no lugar de AttemptTimeout (ex.: curto para APIs rápidas,
+	// longo para as lentas mas confiáveis); APIs ausentes usam AttemptTimeout
+	ProviderAttemptTimeouts map[string]time.Duration
+
+	// Tempo máximo para abrir uma conexão com as APIs (0 usa 30s); ProviderTransports ajusta por API
+	DialTimeout time.Duration
+
+	// Grava (CassetteRecord) ou reproduz (CassetteReplay) as respostas das APIs em CassetteDir
+	CassetteMode string
+	CassetteDir  string
+
+	// Tamanho máximo do trecho do corpo nas mensagens de erro (0 usa 200 bytes)
+	ErrorBodySnippetBytes int
+
+	// Valor usado nos campos vazios do endereço, inclusive as coordenadas, após Transform
+	// (ex.: "N/A"); vazio mantém os campos
+	DefaultFieldValue string
+
+	// Tempo que conexões ociosas ficam abertas (0 usa 30s) e quantas ficam abertas por API
+	// (0 usa o padrão do Go, 2)
+	IdleConnTimeout     time.Duration
+	MaxIdleConnsPerHost int
+
+	// HTTP/2 com as APIs: HTTP2Force sempre tenta negociá-lo, mesmo com transporte ajustado,
+	// e HTTP2Disable usa só HTTP/1.1 (vazio mantém o padrão do Go)
+	HTTP2 string
+
+	// Resolvedor de DNS usado nas conexões com as APIs (ex.: DNS interno ou descoberta de
+	// serviços); nil usa o resolvedor do sistema
+	Resolver *net.Resolver
+
+	// Conexões com hosts IPv4 e IPv6: IPFamily restringe a uma família (IPFamilyIPv4 ou
+	// IPFamilyIPv6, útil quando o IPv4 de uma API está degradado) e DialFallbackDelay é a espera
+	// antes de tentar a outra família (0 usa 300ms, negativo desativa o Happy Eyeballs)
+	IPFamily          string
+	DialFallbackDelay time.Duration
+
+	// Redirecionamentos seguidos por requisição (0 usa 5, negativo não segue nenhum)
+	MaxRedirects int
+
+	// Dá a cada API um http.Client e um pool de conexões próprios, para que uma API lenta não
+	// esgote as conexões das demais; as APIs em ProviderTransports são isoladas mesmo sem a opção
+	IsolateProviders   bool
+	ProviderTransports map[string]ProviderTransport
+
+	// Camadas de APIs consultadas em sequência (ex.: gratuitas e depois pagas); a próxima
+	// camada só corre se a anterior falhar ou se AcceptResult recusar seu resultado
+	ProviderTiers [][]string
+	AcceptResult  func(APIResponse) bool
+
+	// APIs preferidas por prefixo de CEP (ex.: "0" → ViaCEP); para CEPs que casam, elas correm
+	// primeiro e as demais só se falharem (ignorado quando ProviderTiers é definido)
+	RegionPreferences map[string][]string
+
+	// Prazos por prefixo de CEP (ex.: "689" para regiões lentas); vale o prefixo mais longo,
+	// e CEPs sem prefixo configurado usam Timeout
+	PrefixTimeouts map[string]time.Duration
+
+	// Prazos de corridas sucessivas (ex.: 300ms, 1s, 3s), cada uma usada só se a anterior
+	// falhar; vazio faz uma única corrida com Timeout. Substitui Timeout e PrefixTimeouts
+	TimeoutLadder []time.Duration
+
+	// Mantém as demais APIs correndo após a vencedora, por até este tempo além do prazo, e
+	// registra no log a segunda colocada, sem atrasar o retorno (apenas na corrida simples,
+	// sem MinAgreement nem TieBreakerProvider); zero desativa
+	CollectRunnerUp time.Duration
+
+	// Latência máxima aceita para a busca: um resultado que chega depois disso, mesmo dentro do
+	// prazo, é descartado com ErrTooSlow (zero aceita qualquer resultado dentro do prazo)
+	MaxAcceptableLatency time.Duration
+
+	// Chamada com a requisição já montada, antes do envio, para incluir assinaturas ou
+	// cabeçalhos de autenticação; um erro cancela a tentativa
+	SignRequest func(*http.Request) error
+
+	// Tempo que o Client guarda em memória os resultados bem-sucedidos, para que buscas
+	// repetidas do mesmo CEP não consultem as APIs de novo; zero ou negativo desativa
+	CacheTTL time.Duration
+
+	// Buscas simultâneas às APIs no modo servidor (NewHandler), somando todas as requisições;
+	// zero usa defaultServerWorkers
+	ServerWorkers int
+
+	// Definido por WithProviderPriority: as camadas dividem um único prazo em vez de terem um cada
+	sharedTierDeadline bool
+}
+
+// Estrutura para a resposta do BrasilAPI
+type BrasilAPIResponse struct {
+	CEP          string `json:"cep"`
+	State        string `json:"state"`
+	City         string `json:"city"`
+	Neighborhood string `json:"neighborhood"`
+	Street       string `json:"street"`
+	Service      string `json:"service"`
+	Location     struct {
+		Coordinates struct {
+			Latitude  json.Number `json:"latitude"`
+			Longitude json.Number `json:"longitude"`
+		} `json:"coordinates"`
+		Type string `json:"type"`
+	} `json:"location"` // só na v2
+}
+
+// Estrutura para a resposta do ViaCEP. Os campos com extra:"true" fazem parte do formato
+// documentado, mas não do endereço; com CaptureExtraFields eles vão para Address.Extra.
+type ViaCEPResponse struct {
+	CEP         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento" extra:"true"`
+	Unidade     string `json:"unidade" extra:"true"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	UF          string `json:"uf"`
+	Estado      string `json:"estado" extra:"true"`
+	Regiao      string `json:"regiao" extra:"true"`
+	IBGE        string `json:"ibge" extra:"true"`
+	GIA         string `json:"gia" extra:"true"`
+	DDD         string `json:"ddd" extra:"true"`
+	SIAFI       string `json:"siafi" extra:"true"`
+	Erro        any    `json:"erro"` // true (ou "true") quando o CEP não existe
+}
+
+// Estrutura para a resposta do OpenCEP
+type OpenCEPResponse struct {
+	CEP         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento" extra:"true"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	UF          string `json:"uf"`
+	IBGE        string `json:"ibge" extra:"true"`
+}
+
+// Estrutura comum para uso no código
+type Address struct {
+	CEP        string
+	Logradouro string
+	Bairro     string
+	Cidade     string
+	UF         string
+	Latitude   string // só quando a BrasilAPI v2 responde com coordenadas
+	Longitude  string
+	Extra      map[string]string // campos não mapeados da API (só com Config.CaptureExtraFields)
+}
+
+// Estrutura para a resposta da API junto com a fonte
+type APIResponse struct {
+	Result  Address
+	Source  string
+	Headers http.Header // apenas os cabeçalhos listados em Config.CaptureHeaders
+	Timings RequestTimings
+	Date    time.Time // cabeçalho Date da resposta; zero quando ausente ou inválido
+	// Tentativa (a partir de 1) que obteve a resposta; sucessos após a primeira indicam
+	// instabilidade da API. Zero no dataset local e no endereço derivado
+	Attempt int
+	// Resultado veio do cache do Client (Config.CacheTTL), sem consultar as APIs
+	Cached bool
+}
+
+// Age informa há quanto tempo a API gerou a resposta, pelo cabeçalho Date; zero quando
+// a data não está disponível
+func (r APIResponse) Age() time.Duration {
+	if r.Date.IsZero() {
+		return 0
+	}
+	return max(time.Since(r.Date), 0)
+}
+
+// Estrutura para erros detalhados
+type DetailedError struct {
+	API        string
+	Message    string
+	Duration   time.Duration
+	StatusCode int // status HTTP quando a API respondeu fora da faixa 2xx
+	Err        error
+	Locale     string // idioma da mensagem (ver Config.Locale)
+
+	resp *http.Response // resposta HTTP que originou o erro, quando houve uma
+}
+
+func (e *DetailedError) Error() string {
+	return formatDetailedError(e.Locale, e.API, e.Message, e.Duration)
+}
+
+func (e *DetailedError) Unwrap() error {
+	return e.Err
+}
+
+// httpResponse devolve a resposta HTTP associada ao erro, ou nil em falhas de conexão
+func httpResponse(err error) *http.Response {
+	var detailed *DetailedError
+	if errors.As(err, &detailed) {
+		return detailed.resp
+	}
+	return nil
+}
+
+// Status HTTP que disparam nova tentativa quando Config.RetryOnStatus não é definido
+var DefaultRetryOnStatus = []int{429, 500, 502, 503, 504}
+
+// Tamanho máximo do trecho do corpo incluído nas mensagens de erro quando
+// Config.ErrorBodySnippetBytes é 0
+const errorBodySnippetBytes = 200
+
+// ErrNoProviders indica que nenhuma API restou para a busca
+var ErrNoProviders = errors.New("nenhuma API disponível para a busca")
+
+// ErrDryRun é retornado por FetchFastestAPI quando Config.DryRun está ativo
+var ErrDryRun = errors.New("dry-run: nenhuma requisição enviada")
+
+// ErrTimeout indica que o prazo da busca acabou antes de alguma API responder
+var ErrTimeout = errors.New("timeout")
+
+// lookupContextError diferencia o cancelamento pelo chamador (context.Canceled) do fim do prazo
+// (ErrTimeout), para que um servidor possa responder 499 ou 504, por exemplo
+func lookupContextError(parent context.Context) error {
+	if err := parent.Err(); errors.Is(err, context.Canceled) {
+		return fmt.Errorf("busca cancelada: %w", err)
+	}
+	return ErrTimeout
+}
+
+// ErrUnexpectedContentType indica uma resposta que não declara JSON (apenas com StrictContentType)
+var ErrUnexpectedContentType = errors.New("Content-Type inesperado na resposta")
+
+// isJSONContentType aceita application/json e tipos com sufixo +json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// ErrUnknownField indica que a resposta trouxe campos não mapeados (apenas com DisallowUnknownFields)
+var ErrUnknownField = errors.New("campo desconhecido na resposta")
+
+// ErrTruncatedResponse indica um corpo interrompido no meio (conexão encerrada durante a
+// leitura ou JSON incompleto); é tratado como falha transitória e dispara nova tentativa
+var ErrTruncatedResponse = errors.New("resposta truncada")
+
+// ErrCEPNotFound indica que a fonte respondeu, mas não conhece o CEP; não dispara nova tentativa
+var ErrCEPNotFound = errors.New("CEP não encontrado")
+
+var (
+	httpClient = &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:      10,
+			IdleConnTimeout:   30 * time.Second,
+			DisableKeepAlives: false,
+		},
+		CheckRedirect: checkRedirect(defaultMaxRedirects),
+	}
+)
+
+func decodeResponse(body []byte, v any, config Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if config.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	err := decoder.Decode(v)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field") {
+		return fmt.Errorf("%w: %v", ErrUnknownField, err)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrTruncatedResponse, err)
+	}
+	return err
+}
+
+// buildURL acrescenta os segmentos ao caminho da URL base, preservando a query string existente
+func buildURL(base string, elem ...string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return u.JoinPath(elem...).String(), nil
+}
+
+func apiURLs(cep string, config Config) (map[string]string, error) {
+	brasilAPIURL, err := buildURL(config.BrasilAPIURL, providerPath("BrasilAPI", cep)...)
+	if err != nil {
+		return nil, &DetailedError{API: "BrasilAPI", Message: err.Error(), Err: err, Locale: config.Locale}
+	}
+	viaCEPURL, err := buildURL(config.ViaCEPURL, providerPath("ViaCEP", cep)...)
+	if err != nil {
+		return nil, &DetailedError{API: "ViaCEP", Message: err.Error(), Err: err, Locale: config.Locale}
+	}
+	openCEPURL, err := buildURL(config.OpenCEPURL, providerPath("OpenCEP", cep)...)
+	if err != nil {
+		return nil, &DetailedError{API: "OpenCEP", Message: err.Error(), Err: err, Locale: config.Locale}
+	}
+
+	apis := map[string]string{
+		"BrasilAPI": brasilAPIURL,
+		"ViaCEP":    viaCEPURL,
+		"OpenCEP":   openCEPURL,
+	}
+	// Fontes registradas não usam URL; o nome basta para identificá-las
+	for _, name := range registeredNames() {
+		apis[name] = ""
+	}
+	return apis, nil
+}
+
+// selectAPIs devolve as URLs das APIs que participam da busca, sem as excluídas na Config
+func selectAPIs(cep string, config Config) (map[string]string, error) {
+	apis, err := apiURLs(cep, config)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range config.ExcludedProviders {
+		delete(apis, name)
+	}
+	if len(apis) == 0 {
+		return nil, ErrNoProviders
+	}
+	if len(config.StandbyProviders) > 0 {
+		apis = applyStandby(apis, config)
+	}
+	for _, name := range config.DeprecatedProviders {
+		if _, ok := apis[name]; ok {
+			warnDeprecated(name)
+		}
+	}
+	return apis, nil
+}
+
+// newRequest monta a requisição para a API e, se configurado, a assina com Config.SignRequest
+func newRequest(ctx context.Context, url string, config Config) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SignRequest != nil {
+		if err := config.SignRequest(req); err != nil {
+			return nil, fmt.Errorf("erro ao assinar requisição: %w", err)
+		}
+	}
+	return req, nil
+}
+
+func dryRun(ctx context.Context, cep string, config Config) error {
+	apis, err := selectAPIs(cep, config)
+	if err != nil {
+		return err
+	}
+
+	if description, err := json.Marshal(config.Describe()); err == nil {
+		log.Printf("[dry-run] configuração efetiva: %s", description)
+	}
+
+	for source, url := range apis {
+		if _, ok := registeredProvider(source); ok {
+			log.Printf("[dry-run] %s: fonte registrada, sem requisição HTTP", source)
+			continue
+		}
+		req, err := newRequest(ctx, url, config)
+		if err != nil {
+			return &DetailedError{API: source, Message: err.Error(), Err: err, Locale: config.Locale}
+		}
+		if source == "BrasilAPI" && config.BrasilAPIV2URL != "" {
+			v2URL, err := buildURL(config.BrasilAPIV2URL, providerPath("BrasilAPI", cep)...)
+			if err != nil {
+				return &DetailedError{API: source, Message: err.Error(), Err: err, Locale: config.Locale}
+			}
+			log.Printf("[dry-run] %s v2: %s %s timeout=%v (metade do prazo; a v1 abaixo só se falhar)", source, req.Method, v2URL, config.Timeout/2)
+		}
+		log.Printf("[dry-run] %s: %s %s headers=%v timeout=%v", source, req.Method, req.URL, req.Header, config.Timeout)
+	}
+	return ErrDryRun
+}
+
+func fetchAPI(ctx context.Context, cep, url, source string, config Config) (response APIResponse, err error) {
+	start := time.Now()
+	log.Printf("Iniciando requisição para %s (%s)", source, url)
+
+	var recorder timingsRecorder
+	req, err := newRequest(withTimings(ctx, &recorder), url, config)
+	if err != nil {
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  err.Error(),
+			Duration: time.Since(start),
+			Err:      err,
+			Locale:   config.Locale,
+		}
+	}
+
+	resp, err := doRequest(req, cep, source, config)
+	if err != nil {
+		if config.DNSCooldown > 0 && isDNSError(err) {
+			markDNSFailure(source, url, config.DNSCooldown)
+		}
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  err.Error(),
+			Duration: time.Since(start),
+			Err:      err,
+			Locale:   config.Locale,
+		}
+	}
+	defer resp.Body.Close()
+	// Erros após a resposta HTTP guardam a resposta para Config.ShouldRetry
+	defer func() {
+		var detailed *DetailedError
+		if errors.As(err, &detailed) && detailed.resp == nil {
+			detailed.resp = resp
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("%w: %w", ErrTruncatedResponse, err)
+		}
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  err.Error(),
+			Duration: time.Since(start),
+			Err:      err,
+			Locale:   config.Locale,
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return APIResponse{}, &DetailedError{
+			API:        source,
+			Message:    fmt.Sprintf("status %d: %s", resp.StatusCode, bodySnippet(body, config.ErrorBodySnippetBytes)),
+			Duration:   time.Since(start),
+			StatusCode: resp.StatusCode,
+			Locale:     config.Locale,
+		}
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); config.StrictContentType && !isJSONContentType(contentType) {
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  fmt.Sprintf("%v %q: %s", ErrUnexpectedContentType, contentType, bodySnippet(body, config.ErrorBodySnippetBytes)),
+			Duration: time.Since(start),
+			Err:      ErrUnexpectedContentType,
+			Locale:   config.Locale,
+		}
+	}
+
+	body = toUTF8(body, resp.Header.Get("Content-Type"), source)
+
+	decode, ok := providerDecoders[source]
+	if !ok {
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  "API desconhecida",
+			Duration: time.Since(start),
+			Locale:   config.Locale,
+		}
+	}
+	address, label, err := decode(body, config)
+	if err != nil {
+		return APIResponse{}, &DetailedError{
+			API:      source,
+			Message:  err.Error(),
+			Duration: time.Since(start),
+			Err:      err,
+			Locale:   config.Locale,
+		}
+	}
+	if label == "" {
+		label = source
+	}
+
+	timings := recorder.snapshot()
+	log.Printf("Requisição para %s completada em %v (dns=%v conexão=%v tls=%v primeiro byte=%v)",
+		source, time.Since(start), timings.DNS, timings.Connect, timings.TLSHandshake, timings.FirstByte)
+	return APIResponse{
+		Result:  address,
+		Source:  label,
+		Headers: captureHeaders(resp.Header, config.CaptureHeaders),
+		Date:    responseDate(resp.Header),
+		Timings: timings,
+	}, nil
+}
+
+// responseDate lê o cabeçalho Date, que as gravações (cassettes) também preservam
+func responseDate(header http.Header) time.Time {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+func captureHeaders(header http.Header, names []string) http.Header {
+	if len(names) == 0 {
+		return nil
+	}
+
+	captured := make(http.Header)
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			captured[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return captured
+}
+
+// bodySnippet corta o corpo em até limit bytes sem partir caracteres UTF-8 e escapa os
+// caracteres não imprimíveis, para que o trecho seja seguro no log
+func bodySnippet(body []byte, limit int) string {
+	if limit <= 0 {
+		limit = errorBodySnippetBytes
+	}
+
+	truncated := len(body) > limit
+	if truncated {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = body[:cut]
+	}
+
+	var b strings.Builder
+	for _, r := range string(body) {
+		if unicode.IsPrint(r) && r != utf8.RuneError {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "\\u%04x", r)
+		}
+	}
+	if truncated {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// shouldRetry decide se o erro merece nova tentativa: respostas HTTP só quando o status
+// está na lista configurada para a API; demais erros (rede, leitura) sempre.
+func shouldRetry(err error, source string, config Config) bool {
+	if errors.Is(err, ErrTooManyRedirects) {
+		return false
+	}
+	if errors.Is(err, ErrCEPMismatch) {
+		return config.CEPMismatch == CEPMismatchRetry
+	}
+	if errors.Is(err, ErrTruncatedResponse) {
+		return true
+	}
+	if errors.Is(err, ErrUnknownField) {
+		return false // o formato da API mudou; repetir traria a mesma resposta
+	}
+	if errors.Is(err, ErrCEPNotFound) {
+		return false
+	}
+
+	var detailed *DetailedError
+	if !errors.As(err, &detailed) || detailed.StatusCode == 0 {
+		return true
+	}
+
+	return slices.Contains(retryStatuses(source, config), detailed.StatusCode)
+}
+
+// retryStatuses devolve os status HTTP que disparam nova tentativa para a API
+func retryStatuses(source string, config Config) []int {
+	statuses, ok := config.ProviderRetryOnStatus[source]
+	if !ok {
+		statuses = config.RetryOnStatus
+	}
+	if statuses == nil {
+		statuses = DefaultRetryOnStatus
+	}
+	return statuses
+}
+
+// attemptTimeout calcula o limite da tentativa (AttemptTimeout da API * multiplicador^tentativa);
+// zero desativa
+func attemptTimeout(attempt int, source string, config Config) time.Duration {
+	base := config.AttemptTimeout
+	if timeout, ok := config.ProviderAttemptTimeouts[source]; ok {
+		base = timeout
+	}
+	if base <= 0 {
+		return 0
+	}
+
+	multiplier := config.RetryTimeoutMultiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	return time.Duration(float64(base) * math.Pow(multiplier, float64(attempt)))
+}
+
+// fetchAttempt executa uma tentativa; o limite da tentativa nunca ultrapassa o prazo de ctx
+func fetchAttempt(ctx context.Context, cep, url, source string, attempt int, config Config) (APIResponse, error) {
+	if timeout := attemptTimeout(attempt, source, config); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if provider, ok := registeredProvider(source); ok {
+		return lookupProvider(ctx, cep, provider, config)
+	}
+	if source == "BrasilAPI" && config.BrasilAPIV2URL != "" {
+		return fetchBrasilAPIV2(ctx, cep, url, config)
+	}
+	return fetchAPI(ctx, cep, url, source, config)
+}
+
+// fetchBrasilAPIV2 tenta a v2 da BrasilAPI (com coordenadas) e, se falhar, a v1 no mesmo prazo.
+// A v2 usa no máximo metade do tempo restante, para que a v1 ainda tenha chance de responder.
+func fetchBrasilAPIV2(ctx context.Context, cep, v1URL string, config Config) (APIResponse, error) {
+	v2URL, err := buildURL(config.BrasilAPIV2URL, providerPath("BrasilAPI", cep)...)
+	if err == nil {
+		v2Ctx, cancel := ctx, context.CancelFunc(func() {})
+		if deadline, ok := ctx.Deadline(); ok {
+			v2Ctx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		}
+		var response APIResponse
+		response, err = fetchAPI(v2Ctx, cep, v2URL, "BrasilAPI", config)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return APIResponse{}, err
+	}
+
+	log.Printf("BrasilAPI v2 falhou (%v), usando v1", err)
+	return fetchAPI(ctx, cep, v1URL, "BrasilAPI", config)
+}
+
+// Novas tentativas após a primeira em DefaultConfig
+const defaultMaxRetries = 2
+
+// maxAttempts devolve o número de tentativas à API: uma só com DisableRetries, senão a
+// primeira mais as novas tentativas da API (ProviderMaxRetries) ou de MaxRetries
+func maxAttempts(source string, config Config) int {
+	if config.DisableRetries {
+		return 1
+	}
+	if retries, ok := config.ProviderMaxRetries[source]; ok {
+		return 1 + retries
+	}
+	return 1 + max(config.MaxRetries, 0)
+}
+
+// fetchAPIWithRetry faz até attempts tentativas à API; sempre faz ao menos uma, para que a
+// busca nunca termine sem resultado e sem erro
+func fetchAPIWithRetry(ctx context.Context, cep, url, source string, attempts int, config Config) (response APIResponse, err error) {
+	defer func() { recordOutcome(source, err) }()
+	var endpoint string
+
+	attempts = max(attempts, 1)
+	for i := 0; i < attempts; i++ {
+		attemptURL := url
+		if endpoints := config.ProviderEndpoints[source]; len(endpoints) > 0 {
+			endpoint = nextEndpoint(source, endpoints, endpoint)
+			if attemptURL, err = buildURL(endpoint, providerPath(source, cep)...); err != nil {
+				return APIResponse{}, &DetailedError{API: source, Message: err.Error(), Err: err, Locale: config.Locale}
+			}
+		}
+
+		start := time.Now()
+		response, err = fetchAttempt(ctx, cep, attemptURL, source, i, config)
+		if err == nil {
+			err = checkCEPMismatch(response, cep, start, config)
+		}
+		if err == nil && config.ExpandAbbreviations {
+			response.Result = ExpandAbbreviations(response.Result)
+		}
+		if err == nil && config.RetryOnEmpty && isEmptyAddress(response.Result, config.EmptyFields) {
+			err = &DetailedError{
+				API:      source,
+				Message:  ErrEmptyResponse.Error(),
+				Duration: time.Since(start),
+				Err:      ErrEmptyResponse,
+				Locale:   config.Locale,
+			}
+		}
+		recordAttempt(ctx, source, attemptURL, i, err, time.Since(start))
+		if err == nil {
+			// Uma resposta incompleta não vence a corrida, mas também não é repetida
+			if missing := missingFields(response.Result, config.RequiredFields); missing != 0 {
+				return APIResponse{}, &DetailedError{
+					API:      source,
+					Message:  fmt.Sprintf("%v: %v", ErrMissingFields, missing),
+					Duration: time.Since(start),
+					Err:      ErrMissingFields,
+					Locale:   config.Locale,
+				}
+			}
+			response.Attempt = i + 1
+			return response, nil
+		}
+		retry := shouldRetry(err, source, config)
+		if config.ShouldRetry != nil {
+			retry = config.ShouldRetry(i+1, err, httpResponse(err))
+		}
+		if !retry {
+			break
+		}
+
+		// A espera termina assim que outra API vence ou o prazo acaba
+		timer := time.NewTimer(time.Duration(i) * 100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return APIResponse{}, err
+		case <-timer.C:
+		}
+	}
+
+	return APIResponse{}, err
+}
+
+// LookupOption ajusta a Config de uma única chamada a FetchFastestAPI, sem alterar a original
+type LookupOption func(*Config) error
+
+// WithProviderURL substitui a URL base de uma API apenas nesta chamada, para que todas as
+// requisições a ela vão para url: na BrasilAPI desativa a v2 (BrasilAPIV2URL), e os espelhos
+// da API em ProviderEndpoints são ignorados
+func WithProviderURL(name, url string) LookupOption {
+	return func(config *Config) error {
+		if _, ok := config.ProviderEndpoints[name]; ok {
+			config.ProviderEndpoints = maps.Clone(config.ProviderEndpoints)
+			delete(config.ProviderEndpoints, name)
+		}
+		switch name {
+		case "BrasilAPI":
+			config.BrasilAPIURL = url
+			config.BrasilAPIV2URL = ""
+		case "ViaCEP":
+			config.ViaCEPURL = url
+		case "OpenCEP":
+			config.OpenCEPURL = url
+		default:
+			return fmt.Errorf("API desconhecida: %s", name)
+		}
+		return nil
+	}
+}
+
+// WithExcludeProviders remove as APIs informadas da busca apenas nesta chamada
+func WithExcludeProviders(names ...string) LookupOption {
+	return func(config *Config) error {
+		all := slices.Concat(config.ExcludedProviders, names)
+		excluded := make(map[string]bool)
+		for _, name := range all {
+			if !slices.Contains(providerNames(), name) {
+				return fmt.Errorf("API desconhecida: %s", name)
+			}
+			excluded[name] = true
+		}
+		if len(excluded) == len(providerNames()) {
+			return ErrNoProviders
+		}
+
+		config.ExcludedProviders = all
+		return nil
+	}
+}
+
+// WithProviderPriority consulta, apenas nesta chamada, as APIs informadas uma de cada vez e na
+// ordem dada (cada uma vira uma camada de ProviderTiers); as não informadas correm juntas por
+// último. Substitui ProviderTiers e RegionPreferences da Config. Todas as camadas dividem o
+// mesmo prazo (Timeout, ou a soma de TimeoutLadder), que é também a latência máxima da busca:
+// uma API lenta consome o tempo das seguintes.
+func WithProviderPriority(names ...string) LookupOption {
+	return func(config *Config) error {
+		if len(names) == 0 {
+			return errors.New("WithProviderPriority: nenhuma API informada")
+		}
+
+		var tiers [][]string
+		for i, name := range names {
+			if !slices.Contains(providerNames(), name) {
+				return fmt.Errorf("API desconhecida: %s", name)
+			}
+			if slices.Contains(names[:i], name) {
+				return fmt.Errorf("API repetida na prioridade: %s", name)
+			}
+			tiers = append(tiers, []string{name})
+		}
+
+		var others []string
+		for _, name := range providerNames() {
+			if !slices.Contains(names, name) {
+				others = append(others, name)
+			}
+		}
+		if len(others) > 0 {
+			tiers = append(tiers, others)
+		}
+
+		config.ProviderTiers = tiers
+		config.sharedTierDeadline = true
+		return nil
+	}
+}
+
+func FetchFastestAPI(ctx context.Context, cep string, config Config, opts ...LookupOption) (Address, string, error) {
+	response, err := FetchFastestAPIResponse(ctx, cep, config, opts...)
+	if err != nil {
+		return Address{}, "", err
+	}
+	return response.Result, response.Source, nil
+}
+
+// FetchFastestAPIResponse é como FetchFastestAPI, mas devolve a APIResponse completa do vencedor
+func FetchFastestAPIResponse(ctx context.Context, cep string, config Config, opts ...LookupOption) (APIResponse, error) {
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return APIResponse{}, err
+		}
+	}
+
+	if config.PreprocessCEP != nil {
+		translated, err := config.PreprocessCEP(cep)
+		if err != nil {
+			return APIResponse{}, fmt.Errorf("erro ao pré-processar o CEP %q: %w", cep, err)
+		}
+		cep = translated
+	}
+
+	cep, err := ValidateCEP(cep)
+	if err != nil {
+		return APIResponse{}, err
+	}
+	if err := CheckCEPRange(cep, config.AllowedCEPRanges); err != nil {
+		return APIResponse{}, err
+	}
+	config.Timeout = timeoutFor(cep, config)
+
+	if config.DryRun {
+		return APIResponse{}, dryRun(ctx, cep, config)
+	}
+	if config.FailFastWhenAllDown {
+		if err := checkAllProvidersDown(cep, config); err != nil {
+			return APIResponse{}, err
+		}
+	}
+
+	publishMetrics(config.ExpvarNamespace)
+	metrics.lookups.Add(1)
+	metrics.inFlight.Add(1)
+	defer metrics.inFlight.Add(-1)
+
+	start := time.Now()
+	ctx, history := withAttemptHistory(ctx)
+	response, err := fetchFastest(ctx, cep, config)
+	if err != nil {
+		metrics.failures.Add(1)
+		history.log(cep)
+	}
+	localizeError(err, config.Locale)
+	if config.trace != nil {
+		*config.trace = LookupTrace{
+			CEP:      cep,
+			Attempts: history.snapshot(),
+			Source:   response.Source,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+	}
+	audit(ctx, config, cep, response, err)
+	publishEvent(ctx, config, cep, response, err, start)
+	if err == nil {
+		saveResult(ctx, config, cep, response)
+	}
+	return response, err
+}
+
+// Valores de Config.CEPMismatch; vazio apenas registra a divergência no log
+const (
+	CEPMismatchReject = "reject"
+	CEPMismatchRetry  = "retry"
+)
+
+// ErrTooSlow indica um resultado descartado por chegar depois de Config.MaxAcceptableLatency
+var ErrTooSlow = errors.New("resultado chegou depois da latência máxima aceita")
+
+// ErrCEPMismatch indica uma API que respondeu com um CEP diferente do solicitado
+var ErrCEPMismatch = errors.New("API respondeu com outro CEP")
+
+// checkCEPMismatch rejeita, conforme Config.CEPMismatch, a resposta cujo CEP (sem formatação)
+// difere do solicitado; respostas sem CEP não são rejeitadas
+func checkCEPMismatch(response APIResponse, cep string, start time.Time, config Config) error {
+	if config.CEPMismatch == "" {
+		return nil
+	}
+	returned := stripCEPFormatting(response.Result.CEP)
+	if returned == "" || returned == cep {
+		return nil
+	}
+	return &DetailedError{
+		API:      response.Source,
+		Message:  fmt.Sprintf("%v: %s em vez de %s", ErrCEPMismatch, response.Result.CEP, cep),
+		Duration: time.Since(start),
+		Err:      ErrCEPMismatch,
+		Locale:   config.Locale,
+	}
+}
+
+// CanonicalCEPRequested faz Address.CEP ser sempre o CEP solicitado, já normalizado
+const CanonicalCEPRequested = "Requested"
+
+// canonicalCEP escolhe o CEP do resultado conforme Config.CanonicalCEPSource
+func canonicalCEP(response APIResponse, cep string, config Config) string {
+	switch source := config.CanonicalCEPSource; source {
+	case "":
+		if config.UseRequestedCEP {
+			return cep
+		}
+		return response.Result.CEP
+	case CanonicalCEPRequested:
+		return cep
+	default:
+		// A fonte da BrasilAPI pode trazer o serviço interno (ex.: "BrasilAPI/correios")
+		if winner, _, _ := strings.Cut(response.Source, "/"); winner == source {
+			return response.Result.CEP
+		}
+		return cep
+	}
+}
+
+// raceWithLadder faz uma corrida por degrau de Config.TimeoutLadder, até que uma tenha sucesso;
+// se todas falharem, devolve os erros de todos os degraus
+func raceWithLadder(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	if len(config.TimeoutLadder) == 0 {
+		return raceAPIs(ctx, cep, config)
+	}
+
+	var errs []error
+	for i, timeout := range config.TimeoutLadder {
+		rung := config
+		rung.Timeout = timeout
+		response, err := raceAPIs(ctx, cep, rung)
+		if err == nil {
+			return response, nil
+		}
+		errs = append(errs, rungError(config.Locale, i+1, timeout, err))
+		if ctx.Err() != nil || errors.Is(err, ErrNoProviders) {
+			break
+		}
+		log.Printf("Corrida com prazo de %v falhou para o CEP %s: %v", timeout, cep, err)
+	}
+	return APIResponse{}, errors.Join(errs...)
+}
+
+// timeoutFor devolve o prazo do prefixo mais longo de Config.PrefixTimeouts que casa com o CEP,
+// ou Config.Timeout quando nenhum casa
+func timeoutFor(cep string, config Config) time.Duration {
+	timeout, matched := config.Timeout, ""
+	for prefix, t := range config.PrefixTimeouts {
+		if strings.HasPrefix(cep, prefix) && len(prefix) > len(matched) {
+			timeout, matched = t, prefix
+		}
+	}
+	return timeout
+}
+
+func fetchFastest(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	start := time.Now()
+	response, err := raceTiers(ctx, cep, config)
+	if elapsed := time.Since(start); err == nil && config.MaxAcceptableLatency > 0 && elapsed > config.MaxAcceptableLatency {
+		err = fmt.Errorf("%w: %s respondeu em %v (máximo %v)", ErrTooSlow, response.Source, elapsed.Round(time.Millisecond), config.MaxAcceptableLatency)
+		response = APIResponse{}
+	}
+	if err != nil && config.DatasetPath != "" {
+		local, localErr := fetchFromDataset(config.DatasetPath, cep)
+		if localErr == nil {
+			log.Printf("APIs indisponíveis (%v), usando dataset local %s", err, config.DatasetPath)
+			response, err = APIResponse{Result: local, Source: "Local"}, nil
+		} else {
+			log.Println("Erro no dataset local:", localErr)
+		}
+	}
+	if err != nil && config.DerivePartialAddress {
+		if partial, partialErr := BuildPartialFromCEP(cep); partialErr == nil {
+			log.Printf("APIs indisponíveis (%v), devolvendo endereço parcial deduzido do CEP", err)
+			response, err = APIResponse{Result: partial, Source: "Derivado"}, nil
+		}
+	}
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	// Algumas APIs devolvem um CEP diferente do solicitado (CEPs unificados)
+	if returned := stripCEPFormatting(response.Result.CEP); returned != cep {
+		log.Printf("Aviso: %s devolveu o CEP %q para o CEP solicitado %s", response.Source, response.Result.CEP, cep)
+	}
+	response.Result.CEP = canonicalCEP(response, cep, config)
+
+	if config.Transform != nil {
+		response.Result = config.Transform(response.Result)
+	}
+	for _, transform := range config.Transforms {
+		response.Result = transform(response.Result)
+	}
+	if config.ASCIIFold {
+		response.Result = ASCIIFold(response.Result)
+	}
+	if config.DefaultFieldValue != "" {
+		response.Result = fillEmptyFields(response.Result, config.DefaultFieldValue)
+	}
+	return response, nil
+}
+
+// fillEmptyFields preenche com value os campos do endereço que vieram vazios, inclusive as
+// coordenadas (os valores de Extra ficam como a API mandou)
+func fillEmptyFields(address Address, value string) Address {
+	for _, field := range []*string{&address.CEP, &address.Logradouro, &address.Bairro, &address.Cidade, &address.UF, &address.Latitude, &address.Longitude} {
+		if strings.TrimSpace(*field) == "" {
+			*field = value
+		}
+	}
+	return address
+}
+
+func raceAPIs(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	if config.Strategy == StrategyMerge {
+		return mergeAPIs(ctx, cep, config)
+	}
+	if config.MinAgreement > 1 {
+		return quorumAPIs(ctx, cep, config)
+	}
+	if config.TieBreakerProvider != "" {
+		return tieBreakAPIs(ctx, cep, config)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	apis, err := selectAPIs(cep, config)
+	if err != nil {
+		return APIResponse{}, err
+	}
+	apis = activeAPIs(apis)
+
+	result := make(chan APIResponse, len(apis))
+	errChan := make(chan error, len(apis))
+
+	// Para registrar a segunda colocada, as demais APIs não são canceladas quando a primeira
+	// vence e têm até CollectRunnerUp além do prazo para responder
+	fetchCtx, stopFetches := ctx, context.CancelFunc(func() {})
+	if config.CollectRunnerUp > 0 {
+		fetchCtx, stopFetches = context.WithTimeout(context.WithoutCancel(parent), config.Timeout+config.CollectRunnerUp)
+	}
+
+	for source, url := range apis {
+		go func(ctx context.Context, url, source string) {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				response, err := fetchAPIWithRetry(ctx, cep, url, source, maxAttempts(source, config), config)
+				if err != nil {
+					errChan <- err
+					return
+				}
+				// As demais são canceladas pelo retorno de raceAPIs; cancelar aqui faria o
+				// ctx.Done() concorrer, no select abaixo, com esta mesma resposta
+				result <- response
+			}
+		}(fetchCtx, url, source)
+	}
+
+	win := func(res APIResponse) (APIResponse, error) {
+		if config.CollectRunnerUp > 0 {
+			go logRunnerUp(fetchCtx, stopFetches, cep, res, result)
+		} else {
+			stopFetches()
+		}
+		return res, nil
+	}
+
+	// A falha de uma API não encerra a corrida: só desiste quando todas falharem ou o prazo acabar
+	var errs []error
+	for len(errs) < len(apis) {
+		select {
+		case res := <-result:
+			return win(res)
+		case <-ctx.Done():
+			// Uma resposta que chegou junto com o prazo ainda vale
+			select {
+			case res := <-result:
+				return win(res)
+			default:
+			}
+			stopFetches()
+			return APIResponse{}, errors.Join(append([]error{lookupContextError(parent)}, errs...)...)
+		case err := <-errChan:
+			errs = append(errs, err)
+		}
+	}
+
+	stopFetches()
+	return APIResponse{}, errors.Join(errs...)
+}
+
+// logRunnerUp espera, em segundo plano, a segunda API a responder e registra as duas respostas
+// no log para comparação posterior entre as APIs
+func logRunnerUp(ctx context.Context, stop context.CancelFunc, cep string, winner APIResponse, result <-chan APIResponse) {
+	defer stop()
+
+	select {
+	case runnerUp := <-result:
+		log.Printf("Segunda colocada para o CEP %s: %s (%+v); vencedora %s (%+v), concordam: %v",
+			cep, runnerUp.Source, runnerUp.Result, winner.Source, winner.Result, sameCoreFields(winner.Result, runnerUp.Result))
+	case <-ctx.Done():
+		log.Printf("Nenhuma segunda colocada para o CEP %s; vencedora %s", cep, winner.Source)
+	}
+}
@@ -0,0 +1,382 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Corpos válidos de cada API para o CEP 01153000
+const (
+	brasilAPIBody = `{"cep":"01153000","state":"SP","city":"São Paulo","neighborhood":"Barra Funda","street":"Rua Vitorino Carmilo","service":"viacep"}`
+	viaCEPBody    = `{"cep":"01153-000","logradouro":"Rua Vitorino Carmilo","complemento":"","unidade":"","bairro":"Barra Funda","localidade":"São Paulo","uf":"SP","estado":"São Paulo","regiao":"Sudeste","ibge":"3550308","gia":"1004","ddd":"11","siafi":"7107"}`
+)
+
+// stubAPI sobe um servidor que responde com status e corpo após delay
+func stubAPI(t *testing.T, status int, body string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testConfig aponta BrasilAPI e ViaCEP para os servidores e deixa o OpenCEP fora da busca
+func testConfig(brasilAPI, viaCEP *httptest.Server) Config {
+	config := DefaultConfig()
+	config.BrasilAPIURL = brasilAPI.URL + "/"
+	config.ViaCEPURL = viaCEP.URL + "/"
+	config.ExcludedProviders = []string{"OpenCEP"}
+	return config
+}
+
+func TestRaceAPIsFailureDoesNotEndRace(t *testing.T) {
+	brasilAPI := stubAPI(t, http.StatusInternalServerError, `{"message":"erro"}`, 0)
+	viaCEP := stubAPI(t, http.StatusOK, viaCEPBody, 50*time.Millisecond)
+	config := testConfig(brasilAPI, viaCEP)
+	config.DisableRetries = true
+
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Source != "ViaCEP" || response.Result.Logradouro != "Rua Vitorino Carmilo" {
+		t.Errorf("resposta = %s %+v, esperava o endereço do ViaCEP", response.Source, response.Result)
+	}
+}
+
+func TestRaceAPIsAllFailJoinsErrors(t *testing.T) {
+	brasilAPI := stubAPI(t, http.StatusInternalServerError, `{"message":"erro"}`, 0)
+	viaCEP := stubAPI(t, http.StatusBadGateway, "", 0)
+	config := testConfig(brasilAPI, viaCEP)
+	config.DisableRetries = true
+
+	_, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+	if err == nil {
+		t.Fatal("esperava erro com as duas APIs falhando")
+	}
+	for _, name := range []string{"BrasilAPI", "ViaCEP"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("erro %q não menciona %s", err, name)
+		}
+	}
+}
+
+func TestTimeoutLadderGoesPastTimeout(t *testing.T) {
+	slow := stubAPI(t, http.StatusOK, viaCEPBody, 150*time.Millisecond)
+	config := testConfig(slow, slow)
+	config.ExcludedProviders = []string{"BrasilAPI", "OpenCEP"}
+	config.Timeout = 50 * time.Millisecond
+	config.TimeoutLadder = []time.Duration{50 * time.Millisecond, time.Second}
+	config.DisableRetries = true
+
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+	if err != nil {
+		t.Fatalf("o segundo degrau deveria ter tempo para a resposta: %v", err)
+	}
+	if response.Source != "ViaCEP" {
+		t.Errorf("fonte = %s, esperava ViaCEP", response.Source)
+	}
+}
+
+// countingAPI responde sempre com status e corpo e conta as requisições recebidas
+func countingAPI(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*Config)
+		want      int32
+	}{
+		{"padrão", func(c *Config) {}, 3},
+		{"MaxRetries 0", func(c *Config) { c.MaxRetries = 0 }, 1},
+		{"MaxRetries 1", func(c *Config) { c.MaxRetries = 1 }, 2},
+		{"ProviderMaxRetries 0", func(c *Config) { c.ProviderMaxRetries = map[string]int{"BrasilAPI": 0} }, 1},
+		{"DisableRetries", func(c *Config) { c.DisableRetries = true; c.ProviderMaxRetries = map[string]int{"BrasilAPI": 3} }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brasilAPI, hits := countingAPI(t, http.StatusInternalServerError, "")
+			config := testConfig(brasilAPI, brasilAPI)
+			config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+			tt.configure(&config)
+
+			if _, err := FetchFastestAPIResponse(context.Background(), "01153000", config); err == nil {
+				t.Fatal("esperava erro com a API respondendo 500")
+			}
+			if got := hits.Load(); got != tt.want {
+				t.Errorf("tentativas = %d, esperava %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithProviderURLBypassesV2AndEndpoints(t *testing.T) {
+	v2, v2Hits := countingAPI(t, http.StatusOK, "")
+	mirror, mirrorHits := countingAPI(t, http.StatusOK, "")
+	stub := stubAPI(t, http.StatusOK, brasilAPIBody, 0)
+
+	config := DefaultConfig()
+	config.BrasilAPIV2URL = v2.URL + "/"
+	config.ProviderEndpoints = map[string][]ProviderEndpoint{"BrasilAPI": {{URL: mirror.URL + "/", Weight: 1}}}
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", config, WithProviderURL("BrasilAPI", stub.URL+"/"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Result.Logradouro != "Rua Vitorino Carmilo" {
+		t.Errorf("endereço = %+v, esperava o do stub", response.Result)
+	}
+	if v2Hits.Load() != 0 || mirrorHits.Load() != 0 {
+		t.Errorf("requisições à v2 = %d e ao espelho = %d, esperava nenhuma", v2Hits.Load(), mirrorHits.Load())
+	}
+	if len(config.ProviderEndpoints) != 1 {
+		t.Error("WithProviderURL alterou a Config do chamador")
+	}
+}
+
+func TestLookupCanceledVersusDeadline(t *testing.T) {
+	slow := stubAPI(t, http.StatusOK, brasilAPIBody, time.Second)
+
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		cancelRace bool
+		canceled   bool
+	}{
+		{"chamador cancela", time.Second, true, true},
+		{"prazo da busca", 50 * time.Millisecond, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(slow, slow)
+			config.Timeout = tt.timeout
+			config.DisableRetries = true
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelRace {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+
+			_, err := FetchFastestAPIResponse(ctx, "01153000", config)
+			if got := errors.Is(err, context.Canceled); got != tt.canceled {
+				t.Errorf("errors.Is(%v, context.Canceled) = %v, esperava %v", err, got, tt.canceled)
+			}
+			if got := errors.Is(err, ErrTimeout); got == tt.canceled {
+				t.Errorf("errors.Is(%v, ErrTimeout) = %v, esperava %v", err, got, !tt.canceled)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffStopsOnCancel(t *testing.T) {
+	failing, hits := countingAPI(t, http.StatusInternalServerError, "")
+	config := testConfig(failing, failing)
+
+	// Depois de duas falhas rápidas a perdedora espera 100ms; o cancelamento deve encerrá-la antes
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := fetchAPIWithRetry(ctx, "01153000", failing.URL+"/01153000", "BrasilAPI", 3, config)
+	if err == nil {
+		t.Fatal("esperava erro com a API respondendo 500")
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Errorf("fetchAPIWithRetry levou %v após o cancelamento em 30ms, esperando a espera entre tentativas", elapsed)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("requisições = %d, esperava 2 (a terceira cancelada durante a espera)", hits.Load())
+	}
+}
+
+func TestTruncatedResponseIsRetried(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(brasilAPIBody))
+			return
+		}
+		// Primeira tentativa: anuncia o corpo inteiro e fecha a conexão no meio dele
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(brasilAPIBody), brasilAPIBody[:20])
+		buf.Flush()
+	}))
+	t.Cleanup(server.Close)
+
+	config := testConfig(server, server)
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+
+	var trace LookupTrace
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", config, WithTrace(&trace))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if response.Attempt != 2 {
+		t.Errorf("tentativa = %d, esperava 2", response.Attempt)
+	}
+	if len(trace.Attempts) == 0 || !errors.Is(trace.Attempts[0].Err, ErrTruncatedResponse) {
+		t.Errorf("tentativas = %+v, esperava a primeira com ErrTruncatedResponse", trace.Attempts)
+	}
+}
+
+func TestProviderURLTrailingSlash(t *testing.T) {
+	tests := []struct {
+		provider, body, wantPath string
+	}{
+		{"BrasilAPI", brasilAPIBody, "/api/cep/v1/01153000"},
+		{"ViaCEP", viaCEPBody, "/api/cep/v1/01153000/json"},
+	}
+	for _, tt := range tests {
+		for _, suffix := range []string{"", "/"} {
+			t.Run(tt.provider+" "+strconv.Quote(suffix), func(t *testing.T) {
+				var path atomic.Value
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					path.Store(strings.TrimSuffix(r.URL.Path, "/"))
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(tt.body))
+				}))
+				t.Cleanup(server.Close)
+
+				config := testConfig(server, server)
+				config.ExcludedProviders = slices.DeleteFunc([]string{"BrasilAPI", "ViaCEP", "OpenCEP"}, func(name string) bool { return name == tt.provider })
+				config.DisableRetries = true
+
+				_, err := FetchFastestAPIResponse(context.Background(), "01153000", config, WithProviderURL(tt.provider, server.URL+"/api/cep/v1"+suffix))
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				if got, _ := path.Load().(string); got != tt.wantPath {
+					t.Errorf("caminho = %q, esperava %q", got, tt.wantPath)
+				}
+			})
+		}
+	}
+}
+
+func TestLosingGoroutinesExitAfterDeadline(t *testing.T) {
+	// Sem keep-alive, para que as conexões ociosas não contem como goroutines vivas
+	closing := func(status int, delay time.Duration) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	// A BrasilAPI falha rápido e fica na espera entre tentativas; o ViaCEP não responde a tempo
+	failing := closing(http.StatusInternalServerError, 0)
+	slow := closing(http.StatusOK, 5*time.Second)
+	config := testConfig(failing, slow)
+	config.Timeout = 50 * time.Millisecond
+	config.MaxRetries = 5
+
+	baseline := runtime.NumGoroutine()
+	start := time.Now()
+	if _, err := raceAPIs(context.Background(), "01153000", config); err == nil {
+		t.Fatal("esperava erro com o prazo estourado")
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("raceAPIs levou %v com prazo de %v", elapsed, config.Timeout)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		buf := make([]byte, 1<<16)
+		t.Errorf("goroutines = %d após o prazo, esperava no máximo %d:\n%s", n, baseline, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+func TestProviderPrioritySharesDeadline(t *testing.T) {
+	slow := stubAPI(t, http.StatusOK, brasilAPIBody, time.Second)
+	config := testConfig(slow, slow)
+	config.Timeout = 100 * time.Millisecond
+	config.DisableRetries = true
+
+	start := time.Now()
+	_, err := FetchFastestAPIResponse(context.Background(), "01153000", config, WithProviderPriority("BrasilAPI", "ViaCEP"))
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("erro = %v, esperava ErrTimeout", err)
+	}
+	// Cada camada com o próprio prazo levaria 200ms
+	if elapsed := time.Since(start); elapsed > 180*time.Millisecond {
+		t.Errorf("busca levou %v, esperava um único prazo de %v para as duas camadas", elapsed, config.Timeout)
+	}
+}
+
+func TestDefaultFieldValueFillsCoordinates(t *testing.T) {
+	// A BrasilAPI, mais lenta, é a única com coordenadas
+	brasilAPI := stubAPI(t, http.StatusOK, `{"cep":"01153000","state":"SP","city":"São Paulo","neighborhood":"Barra Funda","street":"Rua Vitorino Carmilo","location":{"type":"Point","coordinates":{"latitude":"-23.52","longitude":"-46.65"}}}`, 50*time.Millisecond)
+	viaCEP := stubAPI(t, http.StatusOK, viaCEPBody, 0)
+
+	tests := []struct {
+		name          string
+		strategy      string
+		wantLatitude  string
+		wantLongitude string
+	}{
+		{"vencedora sem coordenadas", StrategyFastest, "N/A", "N/A"},
+		{"coordenadas da perdedora na combinação", StrategyMerge, "-23.52", "-46.65"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(brasilAPI, viaCEP)
+			config.DefaultFieldValue = "N/A"
+			config.DisableRetries = true
+
+			response, err := FetchFastestAPIResponse(context.Background(), "01153000", config, WithStrategy(tt.strategy))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if response.Result.Latitude != tt.wantLatitude || response.Result.Longitude != tt.wantLongitude {
+				t.Errorf("coordenadas = %q, %q; esperava %q, %q", response.Result.Latitude, response.Result.Longitude, tt.wantLatitude, tt.wantLongitude)
+			}
+			if response.Result.Bairro != "Barra Funda" {
+				t.Errorf("bairro = %q, esperava o da API", response.Result.Bairro)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// mergeAPIs consulta todas as APIs e combina as respostas campo a campo: vale o valor não
+// vazio mais frequente e, no empate, o da resposta que chegou primeiro. Se o prazo acabar,
+// combina as respostas já recebidas.
+func mergeAPIs(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	apis, err := selectAPIs(cep, config)
+	if err != nil {
+		return APIResponse{}, err
+	}
+	apis = activeAPIs(apis)
+
+	type outcome struct {
+		response APIResponse
+		err      error
+	}
+	outcomes := make(chan outcome, len(apis))
+	for source, url := range apis {
+		go func(url, source string) {
+			response, err := fetchAPIWithRetry(ctx, cep, url, source, maxAttempts(source, config), config)
+			outcomes <- outcome{response: response, err: err}
+		}(url, source)
+	}
+
+	// Respostas na ordem em que chegaram
+	var responses []APIResponse
+	var errs []error
+	add := func(o outcome) {
+		if o.err != nil {
+			errs = append(errs, o.err)
+			return
+		}
+		responses = append(responses, o.response)
+	}
+collect:
+	for range apis {
+		select {
+		case o := <-outcomes:
+			add(o)
+		case <-ctx.Done():
+			// Respostas que chegaram junto com o prazo ainda entram na combinação
+			for drained := false; !drained; {
+				select {
+				case o := <-outcomes:
+					add(o)
+				default:
+					drained = true
+				}
+			}
+			errs = append(errs, lookupContextError(parent))
+			break collect
+		}
+	}
+	if len(responses) == 0 {
+		return APIResponse{}, errors.Join(errs...)
+	}
+	return mergeResponses(responses), nil
+}
+
+// mergeResponses combina as respostas; a fonte lista as APIs que responderam (ex.:
+// "BrasilAPI+ViaCEP") e os demais dados vêm da primeira
+func mergeResponses(responses []APIResponse) APIResponse {
+	merged := responses[0]
+	values := func(field func(Address) string) []string {
+		all := make([]string, len(responses))
+		for i, r := range responses {
+			all[i] = field(r.Result)
+		}
+		return all
+	}
+
+	merged.Result.Logradouro = mostCommon(values(func(a Address) string { return a.Logradouro }))
+	merged.Result.Bairro = mostCommon(values(func(a Address) string { return a.Bairro }))
+	merged.Result.Cidade = mostCommon(values(func(a Address) string { return a.Cidade }))
+	merged.Result.UF = mostCommon(values(func(a Address) string { return a.UF }))
+	for _, r := range responses {
+		if merged.Result.Latitude == "" && r.Result.Latitude != "" {
+			merged.Result.Latitude, merged.Result.Longitude = r.Result.Latitude, r.Result.Longitude
+		}
+		for key, value := range r.Result.Extra {
+			if _, ok := merged.Result.Extra[key]; !ok {
+				if merged.Result.Extra == nil {
+					merged.Result.Extra = make(map[string]string)
+				}
+				merged.Result.Extra[key] = value
+			}
+		}
+	}
+
+	sources := make([]string, len(responses))
+	for i, r := range responses {
+		sources[i] = r.Source
+	}
+	merged.Source = strings.Join(sources, "+")
+	return merged
+}
+
+// mostCommon devolve o valor não vazio mais frequente, ignorando espaços nas pontas e caixa;
+// no empate, vale o que apareceu primeiro
+func mostCommon(values []string) string {
+	best, bestCount := "", 0
+	for i, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		count := 0
+		for _, other := range values {
+			if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(other)) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = values[i], count
+		}
+	}
+	return best
+}
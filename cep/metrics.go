@@ -0,0 +1,68 @@
+package cep
+
+import (
+	"expvar"
+	"log"
+	"sync"
+)
+
+// Contadores das buscas, publicados em expvar quando Config.ExpvarNamespace é definido
+var metrics struct {
+	lookups   expvar.Int
+	failures  expvar.Int
+	inFlight  expvar.Int
+	cacheHits expvar.Int // buscas do Client respondidas pelo cache, também contadas em lookups
+	providers expvar.Map // por API: success e failure
+
+	droppedEvents expvar.Int // eventos não publicados no EventSink por excesso de publicações
+
+	mu        sync.Mutex
+	published map[string]bool
+}
+
+// publishMetrics publica os contadores em expvar sob o namespace, uma única vez por
+// namespace; um nome já usado por outra variável é apenas logado
+func publishMetrics(namespace string) {
+	if namespace == "" {
+		return
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.published[namespace] {
+		return
+	}
+	if metrics.published == nil {
+		metrics.published = make(map[string]bool)
+	}
+	metrics.published[namespace] = true
+	if expvar.Get(namespace) != nil {
+		log.Printf("Métricas não publicadas: o nome %q já existe em expvar", namespace)
+		return
+	}
+
+	vars := new(expvar.Map)
+	vars.Set("lookups", &metrics.lookups)
+	vars.Set("failures", &metrics.failures)
+	vars.Set("in_flight", &metrics.inFlight)
+	vars.Set("cache_hits", &metrics.cacheHits)
+	vars.Set("providers", &metrics.providers)
+	vars.Set("dropped_events", &metrics.droppedEvents)
+	expvar.Publish(namespace, vars)
+}
+
+// providerMetrics devolve os contadores da API, criando-os na primeira busca
+func providerMetrics(source string) *expvar.Map {
+	if counters, ok := metrics.providers.Get(source).(*expvar.Map); ok {
+		return counters
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if counters, ok := metrics.providers.Get(source).(*expvar.Map); ok {
+		return counters
+	}
+	counters := new(expvar.Map)
+	metrics.providers.Set(source, counters)
+	return counters
+}
@@ -0,0 +1,53 @@
+package cep
+
+import "fmt"
+
+// Faixas de CEP de cada UF, segundo a divisão dos Correios
+var cepRangesByUF = []struct {
+	uf     string
+	ranges []CEPRange
+}{
+	{"SP", []CEPRange{{1000000, 19999999}}},
+	{"RJ", []CEPRange{{20000000, 28999999}}},
+	{"ES", []CEPRange{{29000000, 29999999}}},
+	{"MG", []CEPRange{{30000000, 39999999}}},
+	{"BA", []CEPRange{{40000000, 48999999}}},
+	{"SE", []CEPRange{{49000000, 49999999}}},
+	{"PE", []CEPRange{{50000000, 56999999}}},
+	{"AL", []CEPRange{{57000000, 57999999}}},
+	{"PB", []CEPRange{{58000000, 58999999}}},
+	{"RN", []CEPRange{{59000000, 59999999}}},
+	{"CE", []CEPRange{{60000000, 63999999}}},
+	{"PI", []CEPRange{{64000000, 64999999}}},
+	{"MA", []CEPRange{{65000000, 65999999}}},
+	{"PA", []CEPRange{{66000000, 68899999}}},
+	{"AP", []CEPRange{{68900000, 68999999}}},
+	{"AM", []CEPRange{{69000000, 69299999}, {69400000, 69899999}}},
+	{"RR", []CEPRange{{69300000, 69399999}}},
+	{"AC", []CEPRange{{69900000, 69999999}}},
+	{"DF", []CEPRange{{70000000, 72799999}, {73000000, 73699999}}},
+	{"GO", []CEPRange{{72800000, 72999999}, {73700000, 76799999}}},
+	{"RO", []CEPRange{{76800000, 76999999}}},
+	{"TO", []CEPRange{{77000000, 77999999}}},
+	{"MT", []CEPRange{{78000000, 78899999}}},
+	{"MS", []CEPRange{{79000000, 79999999}}},
+	{"PR", []CEPRange{{80000000, 87999999}}},
+	{"SC", []CEPRange{{88000000, 89999999}}},
+	{"RS", []CEPRange{{90000000, 99999999}}},
+}
+
+// BuildPartialFromCEP deduz a UF a partir das faixas de CEP, sem acessar a rede. O endereço
+// devolvido é parcial: apenas CEP e UF são preenchidos.
+func BuildPartialFromCEP(cep string) (Address, error) {
+	cep, err := ValidateCEP(cep)
+	if err != nil {
+		return Address{}, err
+	}
+
+	for _, entry := range cepRangesByUF {
+		if CheckCEPRange(cep, entry.ranges) == nil {
+			return Address{CEP: cep, UF: entry.uf}, nil
+		}
+	}
+	return Address{}, fmt.Errorf("nenhuma UF atende o CEP %s", cep)
+}
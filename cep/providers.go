@@ -0,0 +1,82 @@
+package cep
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Provider é uma fonte de endereços além das APIs embutidas (ex.: Postmon ou uma API interna
+// da empresa), registrada com RegisterProvider. Lookup recebe o CEP já normalizado (8 dígitos)
+// e deve respeitar o ctx; novas tentativas, prazos e a estratégia da busca ficam com o pacote.
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, cep string) (Address, error)
+}
+
+// Nomes das APIs embutidas, consultadas por HTTP e usados como fonte nas respostas
+var builtinProviders = []string{"BrasilAPI", "ViaCEP", "OpenCEP"}
+
+// Fontes registradas com RegisterProvider, na ordem de registro
+var registry = struct {
+	sync.RWMutex
+	providers map[string]Provider
+	names     []string
+}{providers: make(map[string]Provider)}
+
+// RegisterProvider inclui a fonte em todas as buscas, ao lado das APIs embutidas; pelo nome
+// ela pode ser excluída, priorizada ou posta em uma camada como as demais. Deve ser chamada na
+// inicialização (ex.: em init), antes das buscas; entra em pânico com nome vazio ou repetido.
+func RegisterProvider(p Provider) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	name := p.Name()
+	if name == "" {
+		panic("cep: RegisterProvider com nome vazio")
+	}
+	if _, ok := registry.providers[name]; ok || slices.Contains(builtinProviders, name) {
+		panic("cep: RegisterProvider chamado duas vezes para " + name)
+	}
+	registry.providers[name] = p
+	registry.names = append(registry.names, name)
+}
+
+// providerNames devolve as APIs embutidas seguidas das fontes registradas
+func providerNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return slices.Concat(builtinProviders, registry.names)
+}
+
+// registeredNames devolve só as fontes registradas, na ordem de registro
+func registeredNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return slices.Clone(registry.names)
+}
+
+func registeredProvider(name string) (Provider, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.providers[name]
+	return p, ok
+}
+
+// lookupProvider faz uma tentativa em uma fonte registrada, com os erros no mesmo formato
+// das APIs embutidas
+func lookupProvider(ctx context.Context, cep string, p Provider, config Config) (APIResponse, error) {
+	start := time.Now()
+	address, err := p.Lookup(ctx, cep)
+	if err != nil {
+		return APIResponse{}, &DetailedError{
+			API:      p.Name(),
+			Message:  err.Error(),
+			Duration: time.Since(start),
+			Err:      err,
+			Locale:   config.Locale,
+		}
+	}
+	return APIResponse{Result: address, Source: p.Name()}, nil
+}
@@ -0,0 +1,92 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoAgreement indica que menos de Config.MinAgreement APIs concordaram no endereço
+var ErrNoAgreement = errors.New("APIs não concordaram no endereço")
+
+// sameCoreFields compara os campos usados no modo de concordância (UF, cidade e logradouro)
+func sameCoreFields(a, b Address) bool {
+	return strings.EqualFold(strings.TrimSpace(a.UF), strings.TrimSpace(b.UF)) &&
+		strings.EqualFold(strings.TrimSpace(a.Cidade), strings.TrimSpace(b.Cidade)) &&
+		strings.EqualFold(strings.TrimSpace(a.Logradouro), strings.TrimSpace(b.Logradouro))
+}
+
+// quorumAPIs consulta todas as APIs e só aceita um endereço quando pelo menos
+// config.MinAgreement delas concordam. Entre as que concordam, vale a mais rápida.
+func quorumAPIs(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	apis, err := selectAPIs(cep, config)
+	if err != nil {
+		return APIResponse{}, err
+	}
+	apis = activeAPIs(apis)
+	if config.MinAgreement > len(apis) {
+		return APIResponse{}, fmt.Errorf("%w: MinAgreement %d maior que o número de APIs (%d)", ErrNoAgreement, config.MinAgreement, len(apis))
+	}
+
+	type outcome struct {
+		response APIResponse
+		err      error
+	}
+	outcomes := make(chan outcome, len(apis))
+
+	for source, url := range apis {
+		go func(url, source string) {
+			response, err := fetchAPIWithRetry(ctx, cep, url, source, maxAttempts(source, config), config)
+			outcomes <- outcome{response: response, err: err}
+		}(url, source)
+	}
+
+	// Cada grupo reúne respostas concordantes, na ordem em que chegaram
+	var groups [][]APIResponse
+	var errs []error
+
+	for range apis {
+		var o outcome
+		select {
+		case o = <-outcomes:
+		case <-ctx.Done():
+			// Respostas que chegaram junto com o prazo ainda contam para a concordância
+			select {
+			case o = <-outcomes:
+			default:
+				return APIResponse{}, lookupContextError(parent)
+			}
+		}
+
+		if o.err != nil {
+			errs = append(errs, o.err)
+			continue
+		}
+
+		matched := false
+		for i, group := range groups {
+			if sameCoreFields(group[0].Result, o.response.Result) {
+				groups[i] = append(group, o.response)
+				if len(groups[i]) >= config.MinAgreement {
+					return groups[i][0], nil
+				}
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			groups = append(groups, []APIResponse{o.response})
+		}
+	}
+
+	err = fmt.Errorf("%w (mínimo %d)", ErrNoAgreement, config.MinAgreement)
+	if len(errs) > 0 {
+		err = fmt.Errorf("%w: %w", err, errors.Join(errs...))
+	}
+	return APIResponse{}, err
+}
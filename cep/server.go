@@ -0,0 +1,178 @@
+package cep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Buscas simultâneas no modo servidor quando Config.ServerWorkers é zero
+const defaultServerWorkers = 8
+
+// Limite de CEPs em uma requisição a POST /cep/batch
+const maxBatchSize = 1000
+
+// Resultado de uma busca nas respostas do servidor
+type lookupResult struct {
+	CEP       string       `json:"cep"`
+	Address   *addressJSON `json:"address,omitempty"`
+	Source    string       `json:"source,omitempty"`
+	LatencyMS float64      `json:"latency_ms"`
+	Cached    bool         `json:"cached,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// Endereço nas respostas do servidor, com as mesmas chaves do dataset local
+type addressJSON struct {
+	CEP        string `json:"cep"`
+	Logradouro string `json:"logradouro"`
+	Bairro     string `json:"bairro"`
+	Cidade     string `json:"cidade"`
+	UF         string `json:"uf"`
+	Latitude   string `json:"latitude,omitempty"`
+	Longitude  string `json:"longitude,omitempty"`
+}
+
+// Resposta de uma requisição recusada antes das buscas
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type batchRequest struct {
+	CEPs []string `json:"ceps"`
+}
+
+type batchResponse struct {
+	Results []lookupResult `json:"results"`
+}
+
+// server atende GET /cep/{cep} e POST /cep/batch. Todas as buscas, somando as requisições,
+// disputam slots, para que lotes grandes não multipliquem as conexões com as APIs.
+type server struct {
+	client *Client
+	slots  chan struct{}
+}
+
+// NewHandler expõe as buscas do Client por HTTP: GET /cep/{cep} devolve um resultado e
+// POST /cep/batch, com {"ceps": [...]}, devolve os resultados na ordem recebida. Até
+// workers buscas correm ao mesmo tempo (zero usa defaultServerWorkers).
+func NewHandler(client *Client, workers int) http.Handler {
+	if workers <= 0 {
+		workers = defaultServerWorkers
+	}
+	s := &server{client: client, slots: make(chan struct{}, workers)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cep/{cep}", s.handleLookup)
+	mux.HandleFunc("POST /cep/batch", s.handleBatch)
+	return mux
+}
+
+func (s *server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	result, err := s.lookup(r.Context(), r.PathValue("cep"))
+	status := http.StatusOK
+	if err != nil {
+		status = statusFor(err)
+	}
+	writeJSON(w, status, result)
+}
+
+func (s *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("corpo inválido: %v", err)})
+		return
+	}
+	if len(req.CEPs) > maxBatchSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: fmt.Sprintf("lote com %d CEPs, o limite é %d", len(req.CEPs), maxBatchSize)})
+		return
+	}
+
+	// Cada worker pega o próximo índice; o número de goroutines não passa do de slots
+	results := make([]lookupResult, len(req.CEPs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cap(s.slots), len(req.CEPs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], _ = s.lookup(r.Context(), req.CEPs[i])
+			}
+		}()
+	}
+	for i := range req.CEPs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, batchResponse{Results: results})
+}
+
+// lookup faz a busca em um slot livre; a latência inclui a espera pelo slot
+func (s *server) lookup(ctx context.Context, cep string) (lookupResult, error) {
+	start := time.Now()
+	result := lookupResult{CEP: cep}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		err := lookupContextError(ctx)
+		result.Error = err.Error()
+		return result, err
+	}
+	response, err := s.client.Lookup(ctx, cep)
+	<-s.slots
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	a := response.Result
+	result.Address = &addressJSON{
+		CEP:        a.CEP,
+		Logradouro: a.Logradouro,
+		Bairro:     a.Bairro,
+		Cidade:     a.Cidade,
+		UF:         a.UF,
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+	}
+	result.Source = response.Source
+	result.Cached = response.Cached
+	return result, nil
+}
+
+// statusFor escolhe o status HTTP de uma busca que falhou
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return 499 // convenção do nginx: o cliente desistiu antes da resposta
+	case errors.Is(err, ErrDryRun):
+		return http.StatusAccepted
+	case errors.Is(err, ErrInvalidCEP), errors.Is(err, ErrCEPOutOfRange):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTimeout), errors.Is(err, ErrTooSlow):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrEmptyResponse), errors.Is(err, ErrCEPNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrAllProvidersDown), errors.Is(err, ErrNoProviders):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Erro ao escrever a resposta:", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (l *recordingAuditLogger) Log(ctx context.Context, entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func TestServerCachesAndAuditsLookups(t *testing.T) {
+	brasilAPI, hits := countingAPI(t, http.StatusOK, brasilAPIBody)
+	config := testConfig(brasilAPI, brasilAPI)
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+	config.CacheTTL = time.Minute
+	auditLogger := &recordingAuditLogger{}
+	config.AuditLogger = auditLogger
+
+	server := httptest.NewServer(NewHandler(NewClient(config), 2))
+	defer server.Close()
+
+	for i, want := range []bool{false, true} {
+		resp, err := http.Get(server.URL + "/cep/01153-000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result lookupResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || result.Source != "BrasilAPI" || result.Cached != want {
+			t.Errorf("busca %d: status %d, resultado %+v, esperava cached=%v", i+1, resp.StatusCode, result, want)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("requisições à API = %d, esperava 1 (a segunda vem do cache)", hits.Load())
+	}
+	if len(auditLogger.entries) != 2 {
+		t.Errorf("registros de auditoria = %d, esperava um por busca", len(auditLogger.entries))
+	}
+}
+
+func TestServerBatchKeepsOrder(t *testing.T) {
+	brasilAPI := stubAPI(t, http.StatusOK, brasilAPIBody, 20*time.Millisecond)
+	config := testConfig(brasilAPI, brasilAPI)
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+
+	server := httptest.NewServer(NewHandler(NewClient(config), 2))
+	defer server.Close()
+
+	body := `{"ceps": ["01153000", "x", "01153-000"]}`
+	resp, err := http.Post(server.URL+"/cep/batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var batch batchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Results) != 3 || batch.Results[1].Error == "" || batch.Results[2].CEP != "01153-000" || batch.Results[2].Address == nil {
+		t.Errorf("resultados = %+v", batch.Results)
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrInvalidCEP, http.StatusBadRequest},
+		{ErrTimeout, http.StatusGatewayTimeout},
+		{fmt.Errorf("busca cancelada: %w", context.Canceled), 499},
+		{ErrDryRun, http.StatusAccepted},
+		{errors.Join(ErrEmptyResponse, ErrEmptyResponse), http.StatusNotFound},
+		{errors.New("falha"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		if got := statusFor(tt.err); got != tt.want {
+			t.Errorf("statusFor(%v) = %d, esperava %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cep
+
+import (
+	"context"
+	"log"
+)
+
+// ResultSink recebe o resultado de cada busca bem-sucedida (ex.: cache, auditoria, fila de
+// análise); vários podem ser configurados em Config.ResultSinks
+type ResultSink interface {
+	Save(ctx context.Context, cep string, response APIResponse) error
+}
+
+// Limite de chamadas simultâneas a ResultSinks, somando todas as buscas, para que destinos
+// lentos não acumulem goroutines sem fim
+const maxConcurrentSinks = 8
+
+var sinkSlots = make(chan struct{}, maxConcurrentSinks)
+
+// saveResult entrega o resultado a todos os ResultSinks em segundo plano, em paralelo e sem
+// atrasar a busca; falhas apenas são logadas
+func saveResult(ctx context.Context, config Config, cep string, response APIResponse) {
+	if len(config.ResultSinks) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		for _, sink := range config.ResultSinks {
+			sinkSlots <- struct{}{}
+			go func(sink ResultSink) {
+				defer func() { <-sinkSlots }()
+
+				ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+				defer cancel()
+				if err := sink.Save(ctx, cep, response); err != nil {
+					log.Printf("Erro ao gravar o resultado do CEP %s em %T: %v", cep, sink, err)
+				}
+			}(sink)
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+)
+
+const (
+	// Número de buscas recentes usadas no cálculo da taxa de erro de cada API
+	errorRateWindow = 20
+	// Mínimo de buscas na janela antes de a taxa de erro ser considerada
+	errorRateMinSamples = 5
+	// Taxa de erro que promove as APIs de reserva quando Config.StandbyErrorRate é 0
+	defaultStandbyErrorRate = 0.5
+)
+
+// Resultados recentes (true = erro) de cada API e se a reserva está promovida
+var providerHealth = struct {
+	sync.Mutex
+	recent   map[string][]bool
+	promoted bool
+}{recent: make(map[string][]bool)}
+
+// recordOutcome guarda o resultado final da API em uma busca; cancelamentos (outra API
+// venceu) não contam como erro nem como sucesso
+func recordOutcome(source string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		providerMetrics(source).Add("failure", 1)
+	} else {
+		providerMetrics(source).Add("success", 1)
+	}
+
+	providerHealth.Lock()
+	defer providerHealth.Unlock()
+	recent := append(providerHealth.recent[source], err != nil)
+	if len(recent) > errorRateWindow {
+		recent = recent[len(recent)-errorRateWindow:]
+	}
+	providerHealth.recent[source] = recent
+}
+
+// errorRate devolve a taxa de erro recente da API, ou 0 sem amostras suficientes
+func errorRate(recent []bool) float64 {
+	if len(recent) < errorRateMinSamples {
+		return 0
+	}
+	failures := 0
+	for _, failed := range recent {
+		if failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(recent))
+}
+
+// applyStandby remove as APIs de reserva da busca, exceto quando alguma API ativa está com
+// taxa de erro acima do limite (ou não resta nenhuma ativa); a promoção e a volta à reserva
+// são registradas no log
+func applyStandby(apis map[string]string, config Config) map[string]string {
+	threshold := config.StandbyErrorRate
+	if threshold <= 0 {
+		threshold = defaultStandbyErrorRate
+	}
+
+	providerHealth.Lock()
+	defer providerHealth.Unlock()
+
+	promote, actives := false, 0
+	for name := range apis {
+		if slices.Contains(config.StandbyProviders, name) {
+			continue
+		}
+		actives++
+		if errorRate(providerHealth.recent[name]) >= threshold {
+			promote = true
+		}
+	}
+	promote = promote || actives == 0
+
+	if promote != providerHealth.promoted {
+		providerHealth.promoted = promote
+		if promote {
+			log.Printf("APIs de reserva %v entrando na corrida", config.StandbyProviders)
+		} else {
+			log.Printf("APIs de reserva %v voltando para a reserva", config.StandbyProviders)
+		}
+	}
+	if !promote {
+		for _, name := range config.StandbyProviders {
+			delete(apis, name)
+		}
+	}
+	return apis
+}
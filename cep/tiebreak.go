@@ -0,0 +1,94 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// Config.Timeout/tieBreakReserve fica reservado para a API de desempate; as demais têm o restante
+const tieBreakReserve = 4
+
+// tieBreakAPIs consulta todas as APIs exceto Config.TieBreakerProvider. Se as respostas
+// concordarem, vale a mais rápida; se divergirem, a API de desempate é consultada e sua
+// resposta é preferida. Se alguma API não responder a tempo, vale a mais rápida que respondeu.
+func tieBreakAPIs(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	primaryCtx, cancelPrimaries := context.WithTimeout(ctx, config.Timeout-config.Timeout/tieBreakReserve)
+	defer cancelPrimaries()
+
+	apis, err := selectAPIs(cep, config)
+	if err != nil {
+		return APIResponse{}, err
+	}
+	tieBreaker := config.TieBreakerProvider
+	tieBreakerURL, ok := apis[tieBreaker]
+	delete(apis, tieBreaker)
+	if !ok || len(apis) == 0 {
+		// Sem desempate possível (API excluída ou única restante): corrida normal
+		config.TieBreakerProvider = ""
+		return raceAPIs(parent, cep, config)
+	}
+	apis = activeAPIs(apis)
+
+	type outcome struct {
+		response APIResponse
+		err      error
+	}
+	outcomes := make(chan outcome, len(apis))
+	for source, url := range apis {
+		go func(url, source string) {
+			response, err := fetchAPIWithRetry(primaryCtx, cep, url, source, maxAttempts(source, config), config)
+			outcomes <- outcome{response: response, err: err}
+		}(url, source)
+	}
+
+	// Respostas na ordem em que chegaram
+	var responses []APIResponse
+	var errs []error
+collect:
+	for range apis {
+		var o outcome
+		select {
+		case o = <-outcomes:
+		case <-primaryCtx.Done():
+			// Respostas que chegaram junto com o prazo ainda são comparadas; uma API lenta não
+			// derruba a busca, que segue com as que já responderam
+			select {
+			case o = <-outcomes:
+			default:
+				log.Printf("Nem todas as APIs responderam a tempo para o CEP %s; comparando %d resposta(s)", cep, len(responses))
+				break collect
+			}
+		}
+		if o.err != nil {
+			errs = append(errs, o.err)
+			continue
+		}
+		responses = append(responses, o.response)
+	}
+	if len(responses) == 0 {
+		if primaryCtx.Err() != nil {
+			errs = append([]error{lookupContextError(parent)}, errs...)
+		}
+		return APIResponse{}, errors.Join(errs...)
+	}
+
+	agreed := true
+	for _, r := range responses[1:] {
+		agreed = agreed && sameCoreFields(responses[0].Result, r.Result)
+	}
+	if agreed {
+		return responses[0], nil
+	}
+
+	log.Printf("APIs divergiram para o CEP %s, consultando %s para desempate", cep, tieBreaker)
+	response, err := fetchAPIWithRetry(ctx, cep, tieBreakerURL, tieBreaker, maxAttempts(tieBreaker, config), config)
+	if err != nil {
+		log.Printf("Desempate com %s falhou (%v), usando %s", tieBreaker, err, responses[0].Source)
+		return responses[0], nil
+	}
+	return response, nil
+}
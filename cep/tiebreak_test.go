@@ -0,0 +1,30 @@
+package cep
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTieBreakAPIsSlowPrimaryUsesFastest(t *testing.T) {
+	viaCEP := stubAPI(t, http.StatusOK, viaCEPBody, 0)
+	hung := stubAPI(t, http.StatusOK, viaCEPBody, 5*time.Second)
+	tieBreaker := stubAPI(t, http.StatusOK, brasilAPIBody, 0)
+
+	config := DefaultConfig()
+	config.ViaCEPURL = viaCEP.URL + "/"
+	config.OpenCEPURL = hung.URL + "/"
+	config.BrasilAPIURL = tieBreaker.URL + "/"
+	config.TieBreakerProvider = "BrasilAPI"
+	config.Timeout = 300 * time.Millisecond
+	config.DisableRetries = true
+
+	response, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+	if err != nil {
+		t.Fatalf("erro inesperado com uma API lenta: %v", err)
+	}
+	if response.Source != "ViaCEP" {
+		t.Errorf("fonte = %s, esperava ViaCEP", response.Source)
+	}
+}
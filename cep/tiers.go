@@ -0,0 +1,121 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// raceTiers faz uma corrida por camada de Config.ProviderTiers, na ordem, e para na primeira
+// que produzir um resultado aceito por Config.AcceptResult. Se nenhuma camada tiver resultado
+// aceito, vale o primeiro resultado obtido; sem resultado, os erros de todas as camadas.
+func raceTiers(ctx context.Context, cep string, config Config) (APIResponse, error) {
+	if len(config.ProviderTiers) == 0 && config.Strategy == StrategyFallback {
+		config.ProviderTiers = fallbackTiers(cep, config)
+	}
+	if len(config.ProviderTiers) == 0 {
+		config.ProviderTiers = regionTiers(cep, config)
+	}
+	if len(config.ProviderTiers) == 0 {
+		return raceWithLadder(ctx, cep, config)
+	}
+	if config.sharedTierDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tiersBudget(config))
+		defer cancel()
+	}
+
+	var fallback *APIResponse
+	var errs []error
+	for i, tier := range config.ProviderTiers {
+		tierConfig := config
+		tierConfig.ExcludedProviders = slices.Clone(config.ExcludedProviders)
+		for _, name := range providerNames() {
+			if !slices.Contains(tier, name) {
+				tierConfig.ExcludedProviders = append(tierConfig.ExcludedProviders, name)
+			}
+		}
+
+		response, err := raceWithLadder(ctx, cep, tierConfig)
+		switch {
+		case errors.Is(err, ErrNoProviders):
+			continue
+		case err != nil:
+			errs = append(errs, tierError(config.Locale, i+1, err))
+		case config.AcceptResult == nil || config.AcceptResult(response):
+			return response, nil
+		default:
+			log.Printf("Resultado de %s na camada %d não foi aceito para o CEP %s", response.Source, i+1, cep)
+			if fallback == nil {
+				fallback = &response
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, nil
+	}
+	if len(errs) == 0 {
+		return APIResponse{}, ErrNoProviders
+	}
+	return APIResponse{}, errors.Join(errs...)
+}
+
+// tiersBudget é o prazo somado das camadas com WithProviderPriority: o de uma única corrida
+func tiersBudget(config Config) time.Duration {
+	if len(config.TimeoutLadder) == 0 {
+		return config.Timeout
+	}
+	var total time.Duration
+	for _, timeout := range config.TimeoutLadder {
+		total += timeout
+	}
+	return total
+}
+
+// regionTiers monta duas camadas para o CEP a partir de Config.RegionPreferences: as APIs
+// preferidas para o prefixo mais longo que casa e, depois, as demais; nil quando nenhum casa
+func regionTiers(cep string, config Config) [][]string {
+	var preferred []string
+	matched := ""
+	for prefix, providers := range config.RegionPreferences {
+		if strings.HasPrefix(cep, prefix) && len(prefix) > len(matched) {
+			preferred, matched = providers, prefix
+		}
+	}
+	if preferred == nil {
+		return nil
+	}
+
+	var others []string
+	for _, name := range providerNames() {
+		if !slices.Contains(preferred, name) {
+			others = append(others, name)
+		}
+	}
+	return [][]string{preferred, others}
+}
+
+// fallbackTiers monta uma camada por API para StrategyFallback: primeiro as preferidas para o
+// CEP em Config.RegionPreferences, depois as demais na ordem das APIs
+func fallbackTiers(cep string, config Config) [][]string {
+	var tiers [][]string
+	for _, tier := range regionTiers(cep, config) {
+		for _, name := range tier {
+			tiers = append(tiers, []string{name})
+		}
+	}
+	if tiers != nil {
+		return tiers
+	}
+	for _, name := range providerNames() {
+		tiers = append(tiers, []string{name})
+	}
+	return tiers
+}
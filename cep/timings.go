@@ -0,0 +1,72 @@
+package cep
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Estrutura com a duração de cada fase de uma requisição (zero quando a fase não ocorreu,
+// por exemplo DNS e conexão ao reutilizar uma conexão do pool)
+type RequestTimings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	FirstByte    time.Duration // desde o início da requisição até o primeiro byte da resposta
+}
+
+// Os callbacks do httptrace podem rodar em goroutines do Transport, inclusive após Do retornar
+type timingsRecorder struct {
+	mu      sync.Mutex
+	timings RequestTimings
+}
+
+func (r *timingsRecorder) set(field *time.Duration, since time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*field = time.Since(since)
+}
+
+func (r *timingsRecorder) snapshot() RequestTimings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timings
+}
+
+// withTimings instrumenta o contexto com um httptrace.ClientTrace que alimenta o recorder
+func withTimings(ctx context.Context, r *timingsRecorder) context.Context {
+	var mu sync.Mutex
+	var start, dnsStart, connectStart, tlsStart time.Time
+	mark := func(t *time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		*t = time.Now()
+	}
+	since := func(t *time.Time) time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return *t
+	}
+
+	trace := &httptrace.ClientTrace{
+		GetConn:  func(string) { mark(&start) },
+		DNSStart: func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.set(&r.timings.DNS, since(&dnsStart))
+		},
+		ConnectStart: func(string, string) { mark(&connectStart) },
+		ConnectDone: func(string, string, error) {
+			r.set(&r.timings.Connect, since(&connectStart))
+		},
+		TLSHandshakeStart: func() { mark(&tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.set(&r.timings.TLSHandshake, since(&tlsStart))
+		},
+		GotFirstResponseByte: func() {
+			r.set(&r.timings.FirstByte, since(&start))
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace)
+}
@@ -0,0 +1,180 @@
+package cep
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Estrutura com os ajustes de conexão que exigem um http.Client próprio
+type transportSettings struct {
+	IdleConnTimeout     time.Duration
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	MaxRedirects        int
+	HTTP2               string
+	Resolver            *net.Resolver
+	DialTimeout         time.Duration
+	FallbackDelay       time.Duration
+	IPFamily            string
+	Provider            string // preenchido quando a API tem pool de conexões próprio
+}
+
+// Estrutura com os limites do pool de conexões de uma API isolada; zero mantém o valor global
+type ProviderTransport struct {
+	MaxConnsPerHost     int // conexões simultâneas com a API (0 não limita)
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DialTimeout         time.Duration
+}
+
+// Valores de Config.HTTP2; vazio mantém o padrão do Go (HTTP/2 negociado em https)
+const (
+	HTTP2Force   = "force"
+	HTTP2Disable = "disable"
+)
+
+// Valores de Config.IPFamily; vazio usa IPv4 e IPv6 (Happy Eyeballs)
+const (
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
+
+// dialContext restringe as conexões do dialer à família de endereços IP, quando definida
+func dialContext(dialer *net.Dialer, family string) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "tcp" {
+			switch family {
+			case IPFamilyIPv4:
+				network = "tcp4"
+			case IPFamilyIPv6:
+				network = "tcp6"
+			}
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// Número de redirecionamentos seguidos quando Config.MaxRedirects é 0
+const defaultMaxRedirects = 5
+
+// ErrTooManyRedirects indica um loop de redirecionamentos ou mais do que Config.MaxRedirects
+var ErrTooManyRedirects = errors.New("redirecionamentos demais")
+
+// checkRedirect segue até limit redirecionamentos, registrando cada um no log; com limit
+// negativo não segue nenhum e a resposta 3xx é tratada como erro de status
+func checkRedirect(limit int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if limit < 0 {
+			return http.ErrUseLastResponse
+		}
+		for _, previous := range via {
+			if previous.URL.String() == req.URL.String() {
+				return fmt.Errorf("%w: loop em %s", ErrTooManyRedirects, req.URL)
+			}
+		}
+		if len(via) > limit {
+			return fmt.Errorf("%w: mais de %d para %s", ErrTooManyRedirects, limit, via[0].URL)
+		}
+		log.Printf("Seguindo redirecionamento de %s para %s", via[len(via)-1].URL, req.URL)
+		return nil
+	}
+}
+
+// Clientes HTTP já criados, um por combinação de ajustes, para reaproveitar as conexões
+var httpClients = struct {
+	sync.Mutex
+	byTransport map[transportSettings]*http.Client
+}{byTransport: make(map[transportSettings]*http.Client)}
+
+// clientFor devolve o httpClient do pacote ou, se a Config ajusta as conexões, um cliente
+// compartilhado por todas as buscas com os mesmos ajustes. APIs isoladas (IsolateProviders ou
+// ProviderTransports) recebem um cliente só delas.
+func clientFor(config Config, source string) *http.Client {
+	settings := transportSettings{
+		IdleConnTimeout:     config.IdleConnTimeout,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		MaxRedirects:        config.MaxRedirects,
+		HTTP2:               config.HTTP2,
+		Resolver:            config.Resolver,
+		DialTimeout:         config.DialTimeout,
+		FallbackDelay:       config.DialFallbackDelay,
+		IPFamily:            config.IPFamily,
+	}
+	if t, ok := config.ProviderTransports[source]; ok || config.IsolateProviders {
+		settings.Provider = source
+		if t.MaxConnsPerHost > 0 {
+			settings.MaxConnsPerHost = t.MaxConnsPerHost
+		}
+		if t.MaxIdleConnsPerHost > 0 {
+			settings.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+		}
+		if t.IdleConnTimeout > 0 {
+			settings.IdleConnTimeout = t.IdleConnTimeout
+		}
+		if t.DialTimeout > 0 {
+			settings.DialTimeout = t.DialTimeout
+		}
+	}
+	if settings == (transportSettings{}) {
+		return httpClient
+	}
+
+	httpClients.Lock()
+	defer httpClients.Unlock()
+	if client, ok := httpClients.byTransport[settings]; ok {
+		return client
+	}
+	client := newHTTPClient(httpClient.Transport.(*http.Transport), settings)
+	httpClients.byTransport[settings] = client
+	return client
+}
+
+// newHTTPClient cria um cliente com os ajustes de conexão a partir de uma cópia de base
+func newHTTPClient(base *http.Transport, settings transportSettings) *http.Client {
+	transport := base.Clone()
+	if settings.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = settings.IdleConnTimeout
+	}
+	if settings.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = settings.MaxIdleConnsPerHost
+		transport.MaxIdleConns = max(transport.MaxIdleConns, 3*settings.MaxIdleConnsPerHost)
+	}
+	if settings.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = settings.MaxConnsPerHost
+	}
+	if settings.Resolver != nil || settings.DialTimeout > 0 || settings.FallbackDelay != 0 || settings.IPFamily != "" {
+		dialTimeout := 30 * time.Second
+		if settings.DialTimeout > 0 {
+			dialTimeout = settings.DialTimeout
+		}
+		dialer := &net.Dialer{
+			Timeout:       dialTimeout,
+			KeepAlive:     30 * time.Second,
+			Resolver:      settings.Resolver,
+			FallbackDelay: settings.FallbackDelay,
+		}
+		transport.DialContext = dialContext(dialer, settings.IPFamily)
+		// Com DialContext próprio o Go deixa de negociar HTTP/2 por conta própria
+		transport.ForceAttemptHTTP2 = true
+	}
+	switch settings.HTTP2 {
+	case HTTP2Force:
+		transport.ForceAttemptHTTP2 = true
+	case HTTP2Disable:
+		// Um mapa vazio (não nil) impede a negociação de HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	limit := settings.MaxRedirects
+	if limit == 0 {
+		limit = defaultMaxRedirects
+	}
+	return &http.Client{Transport: transport, CheckRedirect: checkRedirect(limit)}
+}
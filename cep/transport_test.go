@@ -0,0 +1,107 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTP2Setting(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(brasilAPIBody))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// Base igual à do pacote, mas confiando no certificado do servidor de teste
+	base := httpClient.Transport.(*http.Transport).Clone()
+	base.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	tests := []struct {
+		http2     string
+		wantMajor int
+	}{
+		{HTTP2Force, 2},
+		{HTTP2Disable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.http2, func(t *testing.T) {
+			client := newHTTPClient(base, transportSettings{HTTP2: tt.http2})
+			t.Cleanup(client.CloseIdleConnections)
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			resp.Body.Close()
+			if resp.ProtoMajor != tt.wantMajor {
+				t.Errorf("protocolo = %s, esperava HTTP/%d", resp.Proto, tt.wantMajor)
+			}
+		})
+	}
+}
+
+func TestCustomResolverIsUsed(t *testing.T) {
+	if client := clientFor(DefaultConfig(), "BrasilAPI"); client != httpClient || httpClient.Transport.(*http.Transport).DialContext != nil {
+		t.Error("a configuração padrão deveria usar o cliente base, com o resolvedor do sistema")
+	}
+
+	var queried atomic.Bool
+	config := DefaultConfig()
+	config.BrasilAPIURL = "http://brasilapi.cep.invalid/"
+	config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+	config.DisableRetries = true
+	config.DNSCooldown = 0
+	config.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			queried.Store(true)
+			return nil, errors.New("resolvedor de teste")
+		},
+	}
+
+	if _, err := FetchFastestAPIResponse(context.Background(), "01153000", config); err == nil {
+		t.Fatal("esperava erro com o resolvedor falhando")
+	}
+	if !queried.Load() {
+		t.Error("o resolvedor configurado não foi consultado")
+	}
+}
+
+func TestIPFamilyRestrictsDialer(t *testing.T) {
+	server := stubAPI(t, http.StatusOK, brasilAPIBody, 0) // escuta em 127.0.0.1
+
+	config := DefaultConfig()
+	config.DialFallbackDelay = 100 * time.Millisecond
+	if transport := clientFor(config, "BrasilAPI").Transport.(*http.Transport); transport.DialContext == nil {
+		t.Error("DialFallbackDelay deveria usar um dialer próprio")
+	}
+
+	tests := []struct {
+		family string
+		ok     bool
+	}{
+		{IPFamilyIPv4, true},
+		{IPFamilyIPv6, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			config := testConfig(server, server)
+			config.ExcludedProviders = []string{"ViaCEP", "OpenCEP"}
+			config.DisableRetries = true
+			config.IPFamily = tt.family
+			config.DialFallbackDelay = 50 * time.Millisecond
+
+			_, err := FetchFastestAPIResponse(context.Background(), "01153000", config)
+			if (err == nil) != tt.ok {
+				t.Errorf("IPFamily %s com servidor IPv4: erro = %v, esperava sucesso = %v", tt.family, err, tt.ok)
+			}
+		})
+	}
+}
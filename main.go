@@ -2,250 +2,119 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
-)
-
-type Config struct {
-	BrasilAPIURL string
-	ViaCEPURL    string
-	Timeout      time.Duration
-}
-
-// Estrutura para a resposta do BrasilAPI
-type BrasilAPIResponse struct {
-	CEP          string `json:"cep"`
-	State        string `json:"state"`
-	City         string `json:"city"`
-	Neighborhood string `json:"neighborhood"`
-	Street       string `json:"street"`
-	Service      string `json:"service"`
-}
-
-// Estrutura para a resposta do ViaCEP
-type ViaCEPResponse struct {
-	CEP        string `json:"cep"`
-	Logradouro string `json:"logradouro"`
-	Bairro     string `json:"bairro"`
-	Localidade string `json:"localidade"`
-	UF         string `json:"uf"`
-}
 
-// Estrutura comum para uso no código
-type Address struct {
-	CEP        string
-	Logradouro string
-	Bairro     string
-	Cidade     string
-	UF         string
-}
-
-// Estrutura para a resposta da API junto com a fonte
-type APIResponse struct {
-	Result Address
-	Source string
-}
-
-// Estrutura para erros detalhados
-type DetailedError struct {
-	API      string
-	Message  string
-	Duration time.Duration
-}
+	"github.com/pietronirod/multithreading/cep"
+)
 
-func (e *DetailedError) Error() string {
-	return fmt.Sprintf("Erro na API %s: %s (durou %v)", e.API, e.Message, e.Duration)
-}
+// Cache do modo servidor quando CACHE_TTL não é definido
+const defaultServeCacheTTL = 5 * time.Minute
 
-var (
-	httpClient = &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:      10,
-			IdleConnTimeout:   30 * time.Second,
-			DisableKeepAlives: false,
-		},
-	}
+// Prazos do modo servidor: leitura dos cabeçalhos e do corpo (lotes de até 1000 CEPs) e tempo
+// para as buscas em andamento terminarem depois de um SIGINT ou SIGTERM
+const (
+	serveReadHeaderTimeout = 5 * time.Second
+	serveReadTimeout       = 30 * time.Second
+	serveShutdownTimeout   = 10 * time.Second
 )
 
-func loadConfig() Config {
-	brasilAPIURL := os.Getenv("BRASIL_API_URL")
-	if brasilAPIURL == "" {
-		brasilAPIURL = "https://brasilapi.com.br/api/cep/v1/"
+// serveHTTP atende as buscas em addr até receber SIGINT ou SIGTERM e então encerra o servidor
+// sem interromper as requisições em andamento
+func serveHTTP(addr string, config cep.Config) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           cep.NewHandler(cep.NewClient(config), config.ServerWorkers),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+		ReadTimeout:       serveReadTimeout,
 	}
 
-	viaCEPURL := os.Getenv("VIACEP_URL")
-	if viaCEPURL == "" {
-		viaCEPURL = "https://viacep.com.br/ws/"
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	timeoutStr := os.Getenv("API_TIMEOUT")
-	timeout := 1 * time.Second
-	if timeoutStr != "" {
-		if t, err := time.ParseDuration(timeoutStr); err == nil {
-			timeout = t
-		}
-	}
+	errChan := make(chan error, 1)
+	go func() {
+		log.Printf("Servidor ouvindo em %s", addr)
+		errChan <- server.ListenAndServe()
+	}()
 
-	return Config{
-		BrasilAPIURL: brasilAPIURL,
-		ViaCEPURL:    viaCEPURL,
-		Timeout:      timeout,
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
 	}
-}
 
-func fetchAPI(ctx context.Context, url, source string) (Address, error) {
-	start := time.Now()
-	log.Printf("Iniciando requisição para %s (%s)", source, url)
+	log.Println("Encerrando o servidor")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
 
-	var address Address
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return Address{}, &DetailedError{
-			API:      source,
-			Message:  err.Error(),
-			Duration: time.Since(start),
-		}
-	}
+func main() {
+	serve := flag.String("serve", "", "endereço para atender buscas por HTTP (ex.: :8080) em vez de buscar um CEP")
+	flag.Parse()
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return Address{}, &DetailedError{
-			API:      source,
-			Message:  err.Error(),
-			Duration: time.Since(start),
-		}
+	var config cep.Config
+	var err error
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		config, err = cep.LoadConfigFromFile(path)
+	} else {
+		config, err = cep.LoadConfig()
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Address{}, &DetailedError{
-			API:      source,
-			Message:  err.Error(),
-			Duration: time.Since(start),
-		}
+		log.Println("Erro:", err)
+		return
 	}
 
-	switch source {
-	case "BrasilAPI":
-		var brasilResponse BrasilAPIResponse
-		err = json.Unmarshal(body, &brasilResponse)
-		if err != nil {
-			return Address{}, &DetailedError{
-				API:      source,
-				Message:  err.Error(),
-				Duration: time.Since(start),
-			}
-		}
-		address = Address{
-			CEP:        brasilResponse.CEP,
-			Logradouro: brasilResponse.Street,
-			Bairro:     brasilResponse.Neighborhood,
-			Cidade:     brasilResponse.City,
-			UF:         brasilResponse.State,
-		}
-	case "ViaCEP":
-		var viaCEPResponse ViaCEPResponse
-		err = json.Unmarshal(body, &viaCEPResponse)
-		if err != nil {
-			return Address{}, &DetailedError{
-				API:      source,
-				Message:  err.Error(),
-				Duration: time.Since(start),
-			}
-		}
-		address = Address{
-			CEP:        viaCEPResponse.CEP,
-			Logradouro: viaCEPResponse.Logradouro,
-			Bairro:     viaCEPResponse.Bairro,
-			Cidade:     viaCEPResponse.Localidade,
-			UF:         viaCEPResponse.UF,
+	if *serve != "" {
+		if config.CacheTTL == 0 {
+			config.CacheTTL = defaultServeCacheTTL
 		}
-	default:
-		return Address{}, &DetailedError{
-			API:      source,
-			Message:  "API desconhecida",
-			Duration: time.Since(start),
+		if err := serveHTTP(*serve, config); err != nil {
+			log.Println("Erro:", err)
 		}
+		return
 	}
 
-	log.Printf("Requisição para %s completada em %v", source, time.Since(start))
-	return address, nil
-}
-
-func fetchAPIWithRetry(ctx context.Context, url, source string, retries int) (Address, error) {
-	var address Address
-	var err error
-
-	for i := 0; i < retries; i++ {
-		address, err = fetchAPI(ctx, url, source)
-		if err == nil {
-			return address, nil
+	code := "01153000"
+	if flag.NArg() > 0 {
+		code = flag.Arg(0)
+		if strings.TrimSpace(code) == "" {
+			name := filepath.Base(os.Args[0])
+			log.Printf("Uso: %s [CEP]  (ex.: %s 01153-000) ou %s -serve :8080", name, name, name)
+			return
 		}
-		time.Sleep(time.Duration(i) * 100 * time.Millisecond)
 	}
 
-	return Address{}, err
-}
-
-func FetchFastestAPI(ctx context.Context, cep string, config Config) (Address, string, error) {
-	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
-	defer cancel()
-
-	result := make(chan APIResponse, 1)
-	errChan := make(chan error, 2)
-
-	apis := map[string]string{
-		"BrasilAPI": config.BrasilAPIURL + cep,
-		"ViaCEP":    config.ViaCEPURL + cep + "/json",
-	}
-
-	for source, url := range apis {
-		go func(ctx context.Context, url, source string) {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				address, err := fetchAPIWithRetry(ctx, url, source, 3)
-				if err != nil {
-					errChan <- err
-					return
-				}
-				select {
-				case result <- APIResponse{Result: address, Source: source}:
-					cancel()
-				case <-ctx.Done():
-				}
-			}
-		}(ctx, url, source)
+	if _, suggestion, err := cep.ValidateCEPWithSuggestion(code); err != nil {
+		if suggestion != "" {
+			log.Printf("Erro: %v (você quis dizer %s?)", err, suggestion)
+		} else {
+			log.Println("Erro:", err)
+		}
+		return
 	}
 
-	select {
-	case res := <-result:
-		return res.Result, res.Source, nil
-	case <-ctx.Done():
-		return Address{}, "", errors.New("timeout")
-	case err := <-errChan:
-		return Address{}, "", err
+	// Sem prazo aqui: a busca aplica o seu (API_TIMEOUT, CEP_PREFIX_TIMEOUTS ou TIMEOUT_LADDER),
+	// que um prazo externo cortaria
+	response, err := cep.NewClient(config).Lookup(context.Background(), code)
+	if errors.Is(err, cep.ErrDryRun) {
+		return
 	}
-}
-
-func main() {
-	config := loadConfig()
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
-
-	cep := "01153000"
-	result, source, err := FetchFastestAPI(ctx, cep, config)
 	if err != nil {
 		log.Println("Erro:", err)
 	} else {
-		log.Printf("Resultado da API %s: %+v\n", source, result)
+		log.Printf("Resultado da API %s: %+v\n", response.Source, response.Result)
+		if len(response.Headers) > 0 {
+			log.Printf("Cabeçalhos da API %s: %v\n", response.Source, response.Headers)
+		}
 	}
 }